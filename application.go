@@ -1,46 +1,159 @@
 package main
 
 import (
-	"log"
+	"context"
+	"flag"
+	"io/fs"
 	"net/http"
 	"os"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 
+	"github.com/warrenb95/website/internal/blog"
+	"github.com/warrenb95/website/internal/config"
 	handler "github.com/warrenb95/website/internal/http"
+	"github.com/warrenb95/website/internal/storage/awsstore"
+	"github.com/warrenb95/website/internal/storage/local"
+	"github.com/warrenb95/website/internal/templates"
+	"github.com/warrenb95/website/views"
 )
 
 func main() {
-	// AWS Elastic Beanstalk runs off port 5000.
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "5000"
+	sync := flag.Bool("sync", false, "write the blog metadata from the markdown front matter and exit")
+	prune := flag.Bool("prune", false, "with -sync, delete blogs that have no markdown")
+	flag.Parse()
+
+	log := logrus.New()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	// Save the logs here for AWS Elastic Beanstalk.
-	if os.Getenv("ENV") == "PRODUCTION" {
-		f, _ := os.Create("/var/log/blog.log")
+	if cfg.IsProduction() {
+		f, _ := os.Create(cfg.LogFile)
 		defer f.Close()
 		log.SetOutput(f)
 	}
 
-	log := logrus.New()
+	blogs, content, err := newStores(context.Background(), cfg, log)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *sync {
+		report, err := blog.Sync(context.Background(), blogs, content, *prune)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.WithField("synced", report.Synced).WithField("orphaned", report.Orphaned).Info("Synced blogs from front matter")
+		return
+	}
+
+	// Use the embedded views unless we're developing them.
+	var viewsFS fs.FS = views.FS
+	if cfg.IsDevelopment() {
+		viewsFS = os.DirFS(cfg.ViewsDir)
+	}
+	tmpl, err := templates.New(viewsFS, handler.Pages...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if cfg.IsDevelopment() {
+		go tmpl.Watch(context.Background(), time.Second, func(err error) {
+			if err != nil {
+				log.WithError(err).Error("Failed to reload templates")
+				return
+			}
+			log.Info("Reloaded templates")
+		})
+	}
 
-	s := handler.NewServer("eu-west-2", log)
+	s := handler.NewServer(cfg, blogs, content, tmpl, log)
+	go s.WatchIndex(context.Background(), cfg.Index.Refresh)
 
-	r := mux.NewRouter()
+	// Match the escaped path, so a tag with a "/" in it is still one {tag}.
+	r := mux.NewRouter().UseEncodedPath()
 	// Middleware.
 	r.Use(s.Logger)
 
-	fs := http.FileServer(http.Dir("assets"))
-	r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", fs))
+	static := http.FileServer(http.Dir("assets"))
+	r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", static))
+
+	r.NotFoundHandler = s.Logger(http.HandlerFunc(s.NotFound))
 
 	// Server handlers.
 	r.HandleFunc("/", s.Index)
+	r.HandleFunc("/blogs", s.Blogs)
 	r.HandleFunc("/about", s.About)
-	r.HandleFunc("/blog/{title}", s.Show)
+	r.HandleFunc("/tags", s.Tags)
+	r.HandleFunc("/tags/{tag}", s.Tag)
+	r.HandleFunc("/series/{name}", s.Series)
+	r.HandleFunc("/archive", s.Archive)
+	r.HandleFunc("/archive/widget", s.ArchiveWidget)
+	r.HandleFunc("/archive/{year:[0-9]{4}}", s.Archive)
+	r.HandleFunc("/archive/{year:[0-9]{4}}/{month:[0-9]{2}}", s.Archive)
+	r.HandleFunc("/search", s.Search)
+	r.HandleFunc("/search/suggestions", s.SearchSuggestions)
+	r.HandleFunc("/blog/{slug}", s.Show)
+	r.HandleFunc("/og/{slug}.png", s.OGImage)
+	r.HandleFunc("/highlight.css", s.HighlightCSS)
+	r.HandleFunc("/feed.xml", s.RSS)
+	r.HandleFunc("/atom.xml", s.Atom)
+	r.HandleFunc("/tags/{tag}/feed.xml", s.RSS)
+	r.HandleFunc("/tags/{tag}/atom.xml", s.Atom)
+	r.HandleFunc("/sitemap.xml", s.Sitemap)
+	r.HandleFunc("/sitemap-{page:[0-9]+}.xml", s.SitemapPage)
+	r.HandleFunc("/robots.txt", s.Robots)
+	r.HandleFunc("/feed.json", s.JSONFeed)
+	r.HandleFunc("/tags/{tag}/feed.json", s.JSONFeed)
+
+	api := r.PathPrefix("/api/v1").Subrouter()
+	api.HandleFunc("/posts", s.APIPosts).Methods(http.MethodGet)
+	api.HandleFunc("/posts/{slug}", s.APIPost).Methods(http.MethodGet)
+	api.HandleFunc("/admin/cache", s.PurgeCache).Methods(http.MethodDelete)
+	api.HandleFunc("/admin/cache/{slug}", s.PurgeCache).Methods(http.MethodDelete)
+
+	log.Printf("Listening on port %s\n\n", cfg.Port)
+	log.Fatal(http.ListenAndServe(":"+cfg.Port, r))
+}
+
+// newStores creates the blog stores for the configured storage backend.
+func newStores(ctx context.Context, cfg config.Config, logger *logrus.Logger) (blog.BlogStore, blog.ContentStore, error) {
+	if cfg.Storage == config.StorageLocal {
+		// Serve the markdown files on disk, no AWS account needed.
+		store := local.NewStore(cfg.Local.ContentDir, logger)
+		return store, store, nil
+	}
+
+	// Load the Shared AWS Configuration (~/.aws/config)
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(cfg.AWS.Region))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dynamoDBClient := dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
+		if cfg.AWS.EndpointURL != "" {
+			o.BaseEndpoint = aws.String(cfg.AWS.EndpointURL)
+		}
+	})
+	s3Client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.AWS.EndpointURL != "" {
+			o.BaseEndpoint = aws.String(cfg.AWS.EndpointURL)
+			// LocalStack doesn't support virtual hosted buckets.
+			o.UsePathStyle = true
+		}
+	})
+
+	blogs := awsstore.NewBlogStore(dynamoDBClient, cfg.AWS.Table)
+	content := awsstore.NewContentStore(s3Client, cfg.AWS.Bucket, cfg.AWS.KeyPrefix)
 
-	log.Printf("Listening on port %s\n\n", port)
-	log.Fatal(http.ListenAndServe(":"+port, r))
+	return blogs, content, nil
 }
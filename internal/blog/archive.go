@@ -0,0 +1,59 @@
+package blog
+
+import (
+	"sort"
+	"time"
+)
+
+// ArchiveYear is the blogs uploaded in a year, grouped by month newest first.
+type ArchiveYear struct {
+	Year   int
+	Count  int
+	Months []ArchiveMonth
+}
+
+// ArchiveMonth is the blogs uploaded in a month, newest first.
+type ArchiveMonth struct {
+	Year  int
+	Month time.Month
+	Count int
+	Blogs []Blog
+}
+
+// Archive groups the blogs by the year and month they were uploaded in UTC, newest first.
+// Blogs without a valid upload time are left out.
+func Archive(blogs []Blog) []ArchiveYear {
+	type dated struct {
+		Blog
+		uploaded time.Time
+	}
+	var sorted []dated
+	for _, b := range blogs {
+		uploaded, err := b.UploadedAt()
+		if err != nil {
+			continue
+		}
+		sorted = append(sorted, dated{Blog: b, uploaded: uploaded.UTC()})
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].uploaded.After(sorted[j].uploaded)
+	})
+
+	var years []ArchiveYear
+	for _, d := range sorted {
+		year, month := d.uploaded.Year(), d.uploaded.Month()
+		if len(years) == 0 || years[len(years)-1].Year != year {
+			years = append(years, ArchiveYear{Year: year})
+		}
+		y := &years[len(years)-1]
+		if len(y.Months) == 0 || y.Months[len(y.Months)-1].Month != month {
+			y.Months = append(y.Months, ArchiveMonth{Year: year, Month: month})
+		}
+		m := &y.Months[len(y.Months)-1]
+		m.Blogs = append(m.Blogs, d.Blog)
+		m.Count++
+		y.Count++
+	}
+
+	return years
+}
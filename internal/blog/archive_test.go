@@ -0,0 +1,32 @@
+package blog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestArchive(t *testing.T) {
+	blogs := []Blog{
+		{Slug: "jan", Uploaded: "2023-01-05T09:00:00+00:00"},
+		{Slug: "nov", Uploaded: "2023-11-03T20:30:00+00:00"},
+		{Slug: "new_year", Uploaded: "2024-01-01T00:30:00+01:00"},
+		{Slug: "nov_later", Uploaded: "2023-11-20T08:00:00+00:00"},
+		{Slug: "undated"},
+	}
+
+	want := []ArchiveYear{
+		{Year: 2023, Count: 4, Months: []ArchiveMonth{
+			{Year: 2023, Month: time.December, Count: 1, Blogs: []Blog{blogs[2]}},
+			{Year: 2023, Month: time.November, Count: 2, Blogs: []Blog{blogs[3], blogs[1]}},
+			{Year: 2023, Month: time.January, Count: 1, Blogs: []Blog{blogs[0]}},
+		}},
+	}
+	if got := Archive(blogs); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if got := Archive(nil); len(got) != 0 {
+		t.Errorf("no blogs archived %+v", got)
+	}
+}
@@ -0,0 +1,87 @@
+package blog
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by stores when the requested blog or content doesn't exist.
+var ErrNotFound = errors.New("not found")
+
+// Blog struct
+type Blog struct {
+	ID string
+	// Slug is the blog's key and URL. It's stored in the table's "title" key attribute,
+	// where the blogs were keyed before they had separate titles.
+	Slug string `dynamodbav:"title"`
+	// Title is shown to readers, see DefaultTitle for blogs without one.
+	Title         string `dynamodbav:"display_title,omitempty"`
+	ThumbnailPath string `dynamodbav:"thumbnail_path"`
+	Uploaded      string
+	// Updated is when the blog was last edited, in the same layout as Uploaded.
+	Updated      string `dynamodbav:"updated,omitempty"`
+	Summary      string
+	Tags         []string `dynamodbav:"tags,omitempty"`
+	Draft        bool     `dynamodbav:"draft,omitempty"`
+	CanonicalURL string   `dynamodbav:"canonical_url,omitempty"`
+	// Aliases are the blog's old slugs which redirect to it.
+	Aliases []string `dynamodbav:"aliases,omitempty"`
+	// Series is the name of the series the blog is a part of, SeriesPart orders the parts.
+	Series     string        `dynamodbav:"series,omitempty"`
+	SeriesPart int           `dynamodbav:"series_part,omitempty"`
+	Content    template.HTML `dynamodbav:"-"`
+}
+
+// BlogStore stores the blog metadata.
+type BlogStore interface {
+	List(ctx context.Context) ([]Blog, error)
+	Get(ctx context.Context, slug string) (Blog, error)
+	Put(ctx context.Context, b Blog) error
+	Delete(ctx context.Context, slug string) error
+}
+
+// ContentStore stores the blog markdown, including its front matter, and images.
+type ContentStore interface {
+	// ListMarkdown returns the slug of every blog with markdown.
+	ListMarkdown(ctx context.Context) ([]string, error)
+	GetMarkdown(ctx context.Context, slug string) ([]byte, error)
+	// MarkdownVersion returns a version of the blog's markdown that changes whenever it's written,
+	// without reading it.
+	MarkdownVersion(ctx context.Context, slug string) (string, error)
+	PutMarkdown(ctx context.Context, slug string, content []byte) error
+	GetImage(ctx context.Context, name string) ([]byte, error)
+	PutImage(ctx context.Context, name string, data []byte, contentType string) error
+}
+
+// DefaultTitle sets the title from the slug for blogs stored before they had titles.
+func (b *Blog) DefaultTitle() {
+	if b.Title == "" {
+		b.Title = strings.ReplaceAll(b.Slug, "_", " ")
+	}
+}
+
+// UploadedAt parses when the blog was uploaded.
+func (b Blog) UploadedAt() (time.Time, error) {
+	return time.Parse(UploadedLayout, b.Uploaded)
+}
+
+// UpdatedAt parses when the blog was last edited, which is when it was uploaded if it's never been edited.
+func (b Blog) UpdatedAt() (time.Time, error) {
+	if b.Updated == "" {
+		return b.UploadedAt()
+	}
+	return time.Parse(UploadedLayout, b.Updated)
+}
+
+// HasTag reports whether the blog is tagged with tag, ignoring case.
+func (b Blog) HasTag(tag string) bool {
+	for _, t := range b.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package blog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUpdatedAt(t *testing.T) {
+	tests := map[string]struct {
+		blog    Blog
+		want    time.Time
+		wantErr bool
+	}{
+		"updated": {
+			blog: Blog{Uploaded: "2023-11-03T20:00:00+00:00", Updated: "2023-11-05T09:00:00+00:00"},
+			want: time.Date(2023, 11, 5, 9, 0, 0, 0, time.UTC),
+		},
+		"never updated": {
+			blog: Blog{Uploaded: "2023-11-03T20:00:00+00:00"},
+			want: time.Date(2023, 11, 3, 20, 0, 0, 0, time.UTC),
+		},
+		"undated": {
+			blog:    Blog{},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.blog.UpdatedAt()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tc.wantErr)
+			}
+			if !got.Equal(tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHasTag(t *testing.T) {
+	b := Blog{Tags: []string{"go", "AWS"}}
+
+	for tag, want := range map[string]bool{"go": true, "aws": true, "Go": true, "htmx": false, "": false} {
+		if got := b.HasTag(tag); got != want {
+			t.Errorf("HasTag(%q) = %t, want %t", tag, got, want)
+		}
+	}
+}
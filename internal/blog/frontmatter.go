@@ -0,0 +1,159 @@
+package blog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// UploadedLayout is the layout Blog.Uploaded is stored in.
+const UploadedLayout = "2006-01-02T15:04:05-07:00"
+
+var (
+	yamlDelim = []byte("---")
+	tomlDelim = []byte("+++")
+)
+
+// FrontMatter is the metadata block at the top of a markdown file.
+// It's either YAML fenced by "---" or TOML fenced by "+++".
+type FrontMatter struct {
+	ID    string `yaml:"id,omitempty" toml:"id"`
+	Title string `yaml:"title,omitempty" toml:"title"`
+	// Slug has to match the file name when set, the file name is the blog's key.
+	Slug         string    `yaml:"slug,omitempty" toml:"slug"`
+	Date         time.Time `yaml:"date,omitempty" toml:"date"`
+	Updated      time.Time `yaml:"updated,omitempty" toml:"updated"`
+	Summary      string    `yaml:"summary,omitempty" toml:"summary"`
+	Thumbnail    string    `yaml:"thumbnail,omitempty" toml:"thumbnail"`
+	Tags         []string  `yaml:"tags,omitempty" toml:"tags"`
+	Draft        bool      `yaml:"draft,omitempty" toml:"draft"`
+	CanonicalURL string    `yaml:"canonical_url,omitempty" toml:"canonical_url"`
+	// Aliases are old slugs to redirect to this blog, add the old slug when renaming the file.
+	Aliases []string `yaml:"aliases,omitempty" toml:"aliases"`
+	// Series is the name of the series the blog is a part of, SeriesPart orders the parts.
+	Series     string `yaml:"series,omitempty" toml:"series"`
+	SeriesPart int    `yaml:"series_part,omitempty" toml:"series_part"`
+	// TOC shows or hides the table of contents, it's shown for blogs with enough headings otherwise.
+	TOC *bool `yaml:"toc,omitempty" toml:"toc,omitempty"`
+}
+
+// Apply copies the front matter onto the blog's metadata. The blog's slug is left alone as it's the key.
+func (fm FrontMatter) Apply(b *Blog) {
+	b.ID = fm.ID
+	b.Title = fm.Title
+	b.ThumbnailPath = fm.Thumbnail
+	b.Uploaded = formatTime(fm.Date)
+	b.Updated = formatTime(fm.Updated)
+	b.Summary = fm.Summary
+	b.Tags = fm.Tags
+	b.Draft = fm.Draft
+	b.CanonicalURL = fm.CanonicalURL
+	b.Aliases = fm.Aliases
+	b.Series = fm.Series
+	b.SeriesPart = fm.SeriesPart
+}
+
+// Merge copies the blog's metadata onto the front matter, keeping the fields a Blog doesn't have.
+func (fm *FrontMatter) Merge(b Blog) error {
+	date, err := parseTime(b.Uploaded)
+	if err != nil {
+		return fmt.Errorf("parsing uploaded time: %w", err)
+	}
+	updated, err := parseTime(b.Updated)
+	if err != nil {
+		return fmt.Errorf("parsing updated time: %w", err)
+	}
+
+	fm.ID = b.ID
+	fm.Title = b.Title
+	fm.Date = date
+	fm.Updated = updated
+	fm.Summary = b.Summary
+	fm.Thumbnail = b.ThumbnailPath
+	fm.Tags = b.Tags
+	fm.Draft = b.Draft
+	fm.CanonicalURL = b.CanonicalURL
+	fm.Aliases = b.Aliases
+	fm.Series = b.Series
+	fm.SeriesPart = b.SeriesPart
+
+	return nil
+}
+
+// ParseFrontMatter splits the front matter from the markdown body.
+// Content without front matter is returned as is with an empty FrontMatter.
+func ParseFrontMatter(content []byte) (FrontMatter, []byte, error) {
+	var fm FrontMatter
+
+	first, rest := splitLine(content)
+	delim := bytes.TrimRight(first, " \t\r")
+
+	var unmarshal func([]byte, interface{}) error
+	switch {
+	case bytes.Equal(delim, yamlDelim):
+		unmarshal = yaml.Unmarshal
+	case bytes.Equal(delim, tomlDelim):
+		unmarshal = toml.Unmarshal
+	default:
+		return fm, content, nil
+	}
+
+	block := rest
+	for len(rest) > 0 {
+		line, next := splitLine(rest)
+		if bytes.Equal(bytes.TrimRight(line, " \t\r"), delim) {
+			if err := unmarshal(block[:len(block)-len(rest)], &fm); err != nil {
+				return fm, nil, fmt.Errorf("parsing front matter: %w", err)
+			}
+			return fm, bytes.TrimLeft(next, "\r\n"), nil
+		}
+		rest = next
+	}
+
+	return fm, nil, errors.New("unterminated front matter")
+}
+
+// FormatFrontMatter writes fm as YAML front matter followed by the markdown body.
+func FormatFrontMatter(fm FrontMatter, body []byte) ([]byte, error) {
+	block, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling front matter: %w", err)
+	}
+
+	var b bytes.Buffer
+	b.Write(yamlDelim)
+	b.WriteByte('\n')
+	b.Write(block)
+	b.Write(yamlDelim)
+	b.WriteString("\n\n")
+	b.Write(body)
+
+	return b.Bytes(), nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(UploadedLayout)
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(UploadedLayout, s)
+}
+
+// splitLine returns the first line of b, without the newline, and the rest.
+func splitLine(b []byte) ([]byte, []byte) {
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		return b, nil
+	}
+	return b[:i], b[i+1:]
+}
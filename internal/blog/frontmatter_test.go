@@ -0,0 +1,138 @@
+package blog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	date := time.Date(2023, 11, 3, 20, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		content  string
+		wantFM   FrontMatter
+		wantBody string
+		wantErr  string
+	}{
+		"yaml": {
+			content: `---
+title: Deploying to AWS
+slug: deploying_to_aws
+date: 2023-11-03T20:00:00Z
+summary: How the blog is deployed.
+tags: [aws, go]
+draft: true
+canonical_url: https://example.com/deploying
+series: How I built this website
+series_part: 3
+---
+
+# Deploying
+`,
+			wantFM: FrontMatter{
+				Title:        "Deploying to AWS",
+				Slug:         "deploying_to_aws",
+				Date:         date,
+				Summary:      "How the blog is deployed.",
+				Tags:         []string{"aws", "go"},
+				Draft:        true,
+				CanonicalURL: "https://example.com/deploying",
+				Series:       "How I built this website",
+				SeriesPart:   3,
+			},
+			wantBody: "# Deploying\n",
+		},
+		"toml": {
+			content: "+++\r\ntitle = \"Deploying to AWS\"\r\ndate = 2023-11-03T20:00:00Z\r\ntags = [\"aws\"]\r\n+++\r\n# Deploying\r\n",
+			wantFM: FrontMatter{
+				Title: "Deploying to AWS",
+				Date:  date,
+				Tags:  []string{"aws"},
+			},
+			wantBody: "# Deploying\r\n",
+		},
+		"no front matter": {
+			content:  "# Deploying\n\n---\n\nA horizontal rule isn't front matter.\n",
+			wantBody: "# Deploying\n\n---\n\nA horizontal rule isn't front matter.\n",
+		},
+		"empty front matter": {
+			content:  "---\n---\nbody",
+			wantBody: "body",
+		},
+		"unterminated": {
+			content: "---\ntitle: oops\n# Deploying\n",
+			wantErr: "unterminated front matter",
+		},
+		"invalid yaml": {
+			content: "---\ntags: [aws\n---\nbody",
+			wantErr: "parsing front matter",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fm, body, err := ParseFrontMatter([]byte(tc.content))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(fm, tc.wantFM) {
+				t.Errorf("front matter = %+v, want %+v", fm, tc.wantFM)
+			}
+			if string(body) != tc.wantBody {
+				t.Errorf("body = %q, want %q", body, tc.wantBody)
+			}
+		})
+	}
+}
+
+func TestFrontMatterRoundTrip(t *testing.T) {
+	want := Blog{
+		Slug:          "deploying_to_aws",
+		Title:         "Deploying to AWS",
+		Aliases:       []string{"deploying"},
+		ID:            "2",
+		ThumbnailPath: "/static/aws.png",
+		Uploaded:      "2023-11-03T20:00:00+00:00",
+		Updated:       "2023-12-01T09:30:00+00:00",
+		Summary:       "How the blog is deployed.",
+		Tags:          []string{"aws"},
+		CanonicalURL:  "https://example.com/deploying",
+		Series:        "How I built this website",
+		SeriesPart:    3,
+	}
+
+	fm := FrontMatter{Slug: "deploying_to_aws"}
+	if err := fm.Merge(want); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := FormatFrontMatter(fm, []byte("# Deploying\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, body, err := ParseFrontMatter(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Slug != "deploying_to_aws" {
+		t.Errorf("slug = %q, the existing front matter wasn't kept", parsed.Slug)
+	}
+	if string(body) != "# Deploying\n" {
+		t.Errorf("body = %q", body)
+	}
+
+	got := Blog{Slug: want.Slug}
+	parsed.Apply(&got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
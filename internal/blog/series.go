@@ -0,0 +1,37 @@
+package blog
+
+import (
+	"math"
+	"sort"
+)
+
+// SeriesSlug is the series' URL, made from its name.
+func SeriesSlug(name string) string {
+	return Slugify(name)
+}
+
+// InSeries reports whether the blog is a part of the series with the slug.
+func (b Blog) InSeries(slug string) bool {
+	return b.Series != "" && SeriesSlug(b.Series) == slug
+}
+
+// SortParts sorts a series' blogs into reading order, by part number then upload time.
+// Parts without a number go after the numbered ones.
+func SortParts(parts []Blog) {
+	key := func(b Blog) int {
+		if b.SeriesPart > 0 {
+			return b.SeriesPart
+		}
+		return math.MaxInt
+	}
+
+	sort.SliceStable(parts, func(i, j int) bool {
+		ki, kj := key(parts[i]), key(parts[j])
+		if ki != kj {
+			return ki < kj
+		}
+		ti, _ := parts[i].UploadedAt()
+		tj, _ := parts[j].UploadedAt()
+		return ti.Before(tj)
+	})
+}
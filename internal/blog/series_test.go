@@ -0,0 +1,40 @@
+package blog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortParts(t *testing.T) {
+	parts := []Blog{
+		{Slug: "unnumbered_new", Uploaded: "2023-12-01T09:00:00+00:00"},
+		{Slug: "part_2", SeriesPart: 2, Uploaded: "2023-01-01T09:00:00+00:00"},
+		{Slug: "unnumbered_old", Uploaded: "2023-06-01T09:00:00+00:00"},
+		{Slug: "part_1", SeriesPart: 1, Uploaded: "2023-11-01T09:00:00+00:00"},
+	}
+
+	SortParts(parts)
+
+	var got []string
+	for _, p := range parts {
+		got = append(got, p.Slug)
+	}
+	want := []string{"part_1", "part_2", "unnumbered_old", "unnumbered_new"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestInSeries(t *testing.T) {
+	b := Blog{Series: "How I built this website"}
+
+	if !b.InSeries("how_i_built_this_website") {
+		t.Error("blog isn't in its own series")
+	}
+	if b.InSeries("how_i_built_this") {
+		t.Error("blog is in another series")
+	}
+	if (Blog{}).InSeries("") {
+		t.Error("blog without a series is in the empty series")
+	}
+}
@@ -0,0 +1,103 @@
+package blog
+
+import (
+	"sort"
+	"strings"
+)
+
+// Similar returns up to max blogs with a slug or title close to slug, closest first.
+// It's used to suggest the blog a broken link was meant for.
+func Similar(blogs []Blog, slug string, max int) []Blog {
+	target := normaliseTitle(slug)
+	if target == "" {
+		return nil
+	}
+
+	type match struct {
+		blog     Blog
+		distance int
+	}
+
+	var matches []match
+	for _, b := range blogs {
+		// Allow roughly one typo every three characters, or a truncated link.
+		threshold := len([]rune(target)) / 3
+		if threshold < 2 {
+			threshold = 2
+		}
+
+		best := -1
+		for _, candidate := range []string{normaliseTitle(b.Slug), normaliseTitle(b.Title)} {
+			if candidate == "" {
+				continue
+			}
+			d := editDistance(target, candidate)
+			if d > threshold && !strings.Contains(candidate, target) && !strings.Contains(target, candidate) {
+				continue
+			}
+			if best < 0 || d < best {
+				best = d
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		matches = append(matches, match{blog: b, distance: best})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+
+	if len(matches) > max {
+		matches = matches[:max]
+	}
+
+	similar := make([]Blog, 0, len(matches))
+	for _, m := range matches {
+		similar = append(similar, m.blog)
+	}
+
+	return similar
+}
+
+// normaliseTitle makes titles comparable whether they came from a URL or the store.
+func normaliseTitle(title string) string {
+	title = strings.ToLower(strings.TrimSpace(title))
+	return strings.NewReplacer("_", " ", "-", " ").Replace(title)
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func minInt(first int, rest ...int) int {
+	m := first
+	for _, v := range rest {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,90 @@
+package blog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSimilar(t *testing.T) {
+	blogs := []Blog{
+		{Slug: "deploying_to_aws"},
+		{Slug: "deploying_to_k8s"},
+		{Slug: "htmx_and_go"},
+		{Slug: "my_first_blog"},
+	}
+
+	tests := map[string]struct {
+		title string
+		max   int
+		want  []string
+	}{
+		"typo": {
+			title: "htmx_adn_go",
+			max:   3,
+			want:  []string{"htmx_and_go"},
+		},
+		"closest first": {
+			title: "deploying_to_aw",
+			max:   3,
+			want:  []string{"deploying_to_aws", "deploying_to_k8s"},
+		},
+		"limited to max": {
+			title: "deploying_to_aw",
+			max:   1,
+			want:  []string{"deploying_to_aws"},
+		},
+		"truncated link": {
+			title: "my_first",
+			max:   3,
+			want:  []string{"my_first_blog"},
+		},
+		"spaces and case": {
+			title: "My First Blog",
+			max:   3,
+			want:  []string{"my_first_blog"},
+		},
+		"nothing close": {
+			title: "kubernetes_networking_deep_dive",
+			max:   3,
+			want:  []string{},
+		},
+		"empty title": {
+			title: "",
+			max:   3,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			for _, b := range Similar(blogs, tc.title, tc.max) {
+				got = append(got, b.Slug)
+			}
+			if tc.want != nil && got == nil {
+				got = []string{}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"htmx", "htmx", 0},
+		{"héllo", "hello", 1},
+	}
+
+	for _, tc := range tests {
+		if got := editDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package blog
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// maxSlugLength keeps slugs well within the S3 key and DynamoDB key limits.
+const maxSlugLength = 200
+
+// slugPattern is words of letters and digits joined by single underscores or hyphens,
+// so a slug can never be a path like "../" when it's used in an S3 key.
+var slugPattern = regexp.MustCompile(`^[A-Za-z0-9]+(?:[_-][A-Za-z0-9]+)*$`)
+
+// ValidSlug reports whether slug is safe to use as a key and in a URL.
+func ValidSlug(slug string) bool {
+	return len(slug) <= maxSlugLength && slugPattern.MatchString(slug)
+}
+
+// Slugify makes a slug from a title, e.g. "Go & htmx: Part 1" is "go_htmx_part_1".
+// It returns "" when the title has no letters or digits.
+func Slugify(title string) string {
+	var b strings.Builder
+	sep := false
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if sep && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			sep = false
+			b.WriteRune(r)
+		case r == '\'':
+			// Keep contractions together, "I'm" is "im" not "i_m".
+		default:
+			sep = true
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "_")
+	}
+
+	return slug
+}
+
+// Redirects maps every alias to the slug of the blog it now belongs to.
+func Redirects(blogs []Blog) map[string]string {
+	redirects := make(map[string]string)
+	for _, b := range blogs {
+		for _, alias := range b.Aliases {
+			if alias != b.Slug {
+				redirects[alias] = b.Slug
+			}
+		}
+	}
+	return redirects
+}
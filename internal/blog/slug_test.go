@@ -0,0 +1,75 @@
+package blog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidSlug(t *testing.T) {
+	tests := map[string]struct {
+		slug string
+		want bool
+	}{
+		"underscores":        {slug: "htmx_and_go", want: true},
+		"hyphens":            {slug: "deploying-to-aws", want: true},
+		"digits":             {slug: "part_2", want: true},
+		"empty":              {slug: ""},
+		"parent directory":   {slug: ".."},
+		"path":               {slug: "a/b"},
+		"spaces":             {slug: "htmx and go"},
+		"leading separator":  {slug: "_htmx"},
+		"trailing separator": {slug: "htmx_"},
+		"double separator":   {slug: "htmx__go"},
+		"too long":           {slug: strings.Repeat("a", maxSlugLength+1)},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := ValidSlug(tc.slug); got != tc.want {
+				t.Errorf("ValidSlug(%q) = %t, want %t", tc.slug, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]struct {
+		title string
+		want  string
+	}{
+		"punctuation":  {title: "Go & htmx: Part 1", want: "go_htmx_part_1"},
+		"contractions": {title: "I'm Deploying to AWS", want: "im_deploying_to_aws"},
+		"already slug": {title: "htmx_and_go", want: "htmx_and_go"},
+		"non ascii":    {title: "Café Crème", want: "caf_cr_me"},
+		"no words":     {title: "!!!", want: ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := Slugify(tc.title)
+			if got != tc.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tc.title, got, tc.want)
+			}
+			if got != "" && !ValidSlug(got) {
+				t.Errorf("Slugify(%q) = %q isn't a valid slug", tc.title, got)
+			}
+		})
+	}
+}
+
+func TestRedirects(t *testing.T) {
+	blogs := []Blog{
+		{Slug: "htmx_and_go", Aliases: []string{"htmx", "go_and_htmx"}},
+		{Slug: "deploying_to_aws", Aliases: []string{"deploying_to_aws"}},
+		{Slug: "my_first_blog"},
+	}
+
+	want := map[string]string{
+		"htmx":        "htmx_and_go",
+		"go_and_htmx": "htmx_and_go",
+	}
+	if got := Redirects(blogs); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
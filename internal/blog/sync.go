@@ -0,0 +1,78 @@
+package blog
+
+import (
+	"context"
+	"fmt"
+)
+
+// SyncReport is what Sync changed.
+type SyncReport struct {
+	// Synced are the blogs written from their front matter.
+	Synced []string
+	// Orphaned are the blogs in the BlogStore with no markdown, deleted when pruning.
+	Orphaned []string
+}
+
+// Sync writes the front matter of every markdown file to the BlogStore, making the markdown
+// the source of truth for the metadata. Blogs without markdown are deleted when prune is set.
+func Sync(ctx context.Context, blogs BlogStore, content ContentStore, prune bool) (SyncReport, error) {
+	var report SyncReport
+
+	slugs, err := content.ListMarkdown(ctx)
+	if err != nil {
+		return report, fmt.Errorf("listing markdown: %w", err)
+	}
+
+	hasMarkdown := make(map[string]bool, len(slugs))
+	for _, slug := range slugs {
+		hasMarkdown[slug] = true
+
+		if !ValidSlug(slug) {
+			return report, fmt.Errorf("%q isn't a valid slug, try %q", slug, Slugify(slug))
+		}
+
+		md, err := content.GetMarkdown(ctx, slug)
+		if err != nil {
+			return report, fmt.Errorf("getting %q markdown: %w", slug, err)
+		}
+
+		fm, _, err := ParseFrontMatter(md)
+		if err != nil {
+			return report, fmt.Errorf("%q: %w", slug, err)
+		}
+		if fm.Slug != "" && fm.Slug != slug {
+			return report, fmt.Errorf("%q: slug %q doesn't match the file name", slug, fm.Slug)
+		}
+		for _, alias := range fm.Aliases {
+			if !ValidSlug(alias) {
+				return report, fmt.Errorf("%q: alias %q isn't a valid slug", slug, alias)
+			}
+		}
+
+		b := Blog{Slug: slug}
+		fm.Apply(&b)
+		if err := blogs.Put(ctx, b); err != nil {
+			return report, fmt.Errorf("putting %q: %w", slug, err)
+		}
+		report.Synced = append(report.Synced, slug)
+	}
+
+	existing, err := blogs.List(ctx)
+	if err != nil {
+		return report, fmt.Errorf("listing blogs: %w", err)
+	}
+	for _, b := range existing {
+		if hasMarkdown[b.Slug] {
+			continue
+		}
+		report.Orphaned = append(report.Orphaned, b.Slug)
+
+		if prune {
+			if err := blogs.Delete(ctx, b.Slug); err != nil {
+				return report, fmt.Errorf("deleting %q: %w", b.Slug, err)
+			}
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,98 @@
+package blog_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/warrenb95/website/internal/blog"
+	"github.com/warrenb95/website/internal/storage/memory"
+)
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		markdown     map[string]string
+		prune        bool
+		wantBlogs    []blog.Blog
+		wantOrphaned []string
+		wantErr      string
+	}{
+		"writes front matter to the blog store": {
+			markdown: map[string]string{
+				"htmx_and_go": "---\ndate: 2023-11-03T20:00:00Z\nsummary: Using htmx with Go.\ntags: [go, htmx]\n---\n# htmx\n",
+				"no_metadata": "# No metadata\n",
+			},
+			wantBlogs: []blog.Blog{
+				{Slug: "htmx_and_go", Uploaded: "2023-11-03T20:00:00+00:00", Summary: "Using htmx with Go.", Tags: []string{"go", "htmx"}},
+				{Slug: "no_metadata"},
+				{Slug: "orphan", Summary: "was deleted"},
+			},
+			wantOrphaned: []string{"orphan"},
+		},
+		"prunes orphans": {
+			markdown: map[string]string{
+				"no_metadata": "# No metadata\n",
+			},
+			prune:        true,
+			wantBlogs:    []blog.Blog{{Slug: "no_metadata"}},
+			wantOrphaned: []string{"orphan"},
+		},
+		"slug must match the file name": {
+			markdown: map[string]string{
+				"htmx_and_go": "---\nslug: htmx\n---\n# htmx\n",
+			},
+			wantErr: `slug "htmx" doesn't match the file name`,
+		},
+		"invalid front matter": {
+			markdown: map[string]string{
+				"htmx_and_go": "---\nsummary: oops\n",
+			},
+			wantErr: "unterminated front matter",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := memory.NewStore()
+			if err := store.Put(ctx, blog.Blog{Slug: "orphan", Summary: "was deleted"}); err != nil {
+				t.Fatal(err)
+			}
+			for slug, md := range tc.markdown {
+				if err := store.PutMarkdown(ctx, slug, []byte(md)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			report, err := blog.Sync(ctx, store, store, tc.prune)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(report.Orphaned, tc.wantOrphaned) {
+				t.Errorf("orphaned = %v, want %v", report.Orphaned, tc.wantOrphaned)
+			}
+
+			for _, want := range tc.wantBlogs {
+				got, err := store.Get(ctx, want.Slug)
+				if err != nil {
+					t.Fatalf("getting %q: %v", want.Slug, err)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("got %+v, want %+v", got, want)
+				}
+			}
+			blogs, _ := store.List(ctx)
+			if len(blogs) != len(tc.wantBlogs) {
+				t.Errorf("store has %d blogs, want %d", len(blogs), len(tc.wantBlogs))
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package blog
+
+import (
+	"sort"
+	"strings"
+)
+
+// TagCount is how many blogs have a tag.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// CountTags counts the blogs with each tag, ignoring case, sorted by tag.
+// A tag is named the way most blogs write it, the first spelling in sort order on a tie, so it doesn't
+// depend on the order of the blogs.
+func CountTags(blogs []Blog) []TagCount {
+	index := make(map[string]int)
+	var counts []TagCount
+	// spellings counts the blogs writing each tag each way.
+	spellings := make(map[string]map[string]int)
+	for _, b := range blogs {
+		seen := make(map[string]bool)
+		for _, tag := range b.Tags {
+			key := strings.ToLower(tag)
+			if tag == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			i, ok := index[key]
+			if !ok {
+				i = len(counts)
+				index[key] = i
+				counts = append(counts, TagCount{Tag: tag})
+				spellings[key] = make(map[string]int)
+			}
+			counts[i].Count++
+			spellings[key][tag]++
+		}
+	}
+
+	for key, i := range index {
+		best := ""
+		for spelling, n := range spellings[key] {
+			if best == "" || n > spellings[key][best] || (n == spellings[key][best] && spelling < best) {
+				best = spelling
+			}
+		}
+		counts[i].Tag = best
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		return strings.ToLower(counts[i].Tag) < strings.ToLower(counts[j].Tag)
+	})
+
+	return counts
+}
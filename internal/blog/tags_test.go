@@ -0,0 +1,30 @@
+package blog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCountTags(t *testing.T) {
+	blogs := []Blog{
+		{Tags: []string{"Go", "htmx"}},
+		{Tags: []string{"go", "AWS", "GO"}},
+		{Tags: []string{"aws", ""}},
+		{Tags: []string{"HTMX"}},
+		{Tags: []string{"htmx"}},
+		{},
+	}
+
+	want := []TagCount{
+		{Tag: "AWS", Count: 2},
+		{Tag: "Go", Count: 2},
+		{Tag: "htmx", Count: 3},
+	}
+	if got := CountTags(blogs); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if got := CountTags(nil); len(got) != 0 {
+		t.Errorf("no blogs counted %+v", got)
+	}
+}
@@ -0,0 +1,155 @@
+// Package cache is an in-memory LRU cache whose entries expire. Values missing from it are loaded once
+// however many callers ask for them at the same time.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Options bound a cache, leave any at 0 for no bound.
+type Options struct {
+	// MaxEntries is how many entries are kept, the least recently used are evicted first.
+	MaxEntries int
+	// MaxBytes is how big the entries can be in all, as the cache's size function measures them.
+	// Values bigger than it on their own aren't cached.
+	MaxBytes int
+	// TTL is how long entries are kept after they're added.
+	TTL time.Duration
+	// LoadTimeout bounds GetOrLoad's loads.
+	LoadTimeout time.Duration
+}
+
+// Cache maps keys to values, it's safe for concurrent use.
+type Cache[V any] struct {
+	opts Options
+	size func(V) int
+	now  func() time.Time
+
+	mu    sync.Mutex
+	lru   *list.List // of *entry[V], most recently used first.
+	items map[string]*list.Element
+	bytes int
+
+	loads Group[V]
+}
+
+type entry[V any] struct {
+	key     string
+	value   V
+	size    int
+	expires time.Time
+}
+
+// New returns an empty cache. size measures values for MaxBytes, it can be nil without it.
+func New[V any](opts Options, size func(V) int) *Cache[V] {
+	if size == nil {
+		size = func(V) int { return 0 }
+	}
+	return &Cache[V]{
+		opts:  opts,
+		size:  size,
+		now:   time.Now,
+		lru:   list.New(),
+		items: make(map[string]*list.Element),
+		loads: Group[V]{Timeout: opts.LoadTimeout},
+	}
+}
+
+// Get returns the value for key and whether it's cached.
+func (c *Cache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	e := el.Value.(*entry[V])
+	if !e.expires.IsZero() && !c.now().Before(e.expires) {
+		c.remove(el)
+		var zero V
+		return zero, false
+	}
+	c.lru.MoveToFront(el)
+	return e.value, true
+}
+
+// Add caches the value for key, replacing any there is, and evicts the least recently used entries
+// until the cache is within its bounds.
+func (c *Cache[V]) Add(key string, value V) {
+	size := c.size(value)
+	if c.opts.MaxBytes > 0 && size > c.opts.MaxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+	e := &entry[V]{key: key, value: value, size: size}
+	if c.opts.TTL > 0 {
+		e.expires = c.now().Add(c.opts.TTL)
+	}
+	c.items[key] = c.lru.PushFront(e)
+	c.bytes += size
+
+	for (c.opts.MaxEntries > 0 && c.lru.Len() > c.opts.MaxEntries) || (c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes) {
+		c.remove(c.lru.Back())
+	}
+}
+
+// GetOrLoad returns the value for key, loading and caching it if it's not cached. Callers asking for
+// the same key while it's loading wait for that load rather than starting their own, and get its error
+// too. Errors aren't cached. The load isn't cancelled with ctx, see Group.Do.
+func (c *Cache[V]) GetOrLoad(ctx context.Context, key string, load func(context.Context) (V, error)) (V, error) {
+	if v, ok := c.Get(key); ok {
+		return v, nil
+	}
+	return c.loads.Do(ctx, key, func(ctx context.Context) (V, error) {
+		// It may have been loaded since it was missed.
+		if v, ok := c.Get(key); ok {
+			return v, nil
+		}
+		v, err := load(ctx)
+		if err != nil {
+			return v, err
+		}
+		c.Add(key, v)
+		return v, nil
+	})
+}
+
+// DeleteFunc removes the entries whose keys match and returns how many it removed.
+func (c *Cache[V]) DeleteFunc(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for key, el := range c.items {
+		if match(key) {
+			c.remove(el)
+			n++
+		}
+	}
+	return n
+}
+
+// Len returns how many entries are cached, including those that have expired but not been removed.
+func (c *Cache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// remove removes the entry, c.mu must be held.
+func (c *Cache[V]) remove(el *list.Element) {
+	e := c.lru.Remove(el).(*entry[V])
+	delete(c.items, e.key)
+	c.bytes -= e.size
+}
@@ -0,0 +1,250 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	tests := map[string]struct {
+		opts Options
+		// adds are added in order, then each key is got.
+		adds []string
+		want []string
+	}{
+		"unbounded": {
+			adds: []string{"a", "bb", "ccc"},
+			want: []string{"a", "bb", "ccc"},
+		},
+		"max entries": {
+			opts: Options{MaxEntries: 2},
+			adds: []string{"a", "bb", "ccc"},
+			want: []string{"bb", "ccc"},
+		},
+		"max bytes": {
+			opts: Options{MaxBytes: 5},
+			adds: []string{"a", "bb", "ccc"},
+			want: []string{"bb", "ccc"},
+		},
+		"too big": {
+			opts: Options{MaxBytes: 2},
+			adds: []string{"a", "ccc"},
+			want: []string{"a"},
+		},
+		"replaced": {
+			opts: Options{MaxEntries: 2},
+			adds: []string{"a", "bb", "a", "ccc"},
+			want: []string{"a", "ccc"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := New(tc.opts, func(v string) int { return len(v) })
+			for _, key := range tc.adds {
+				c.Add(key, key)
+			}
+
+			var got []string
+			for _, key := range []string{"a", "bb", "ccc"} {
+				if v, ok := c.Get(key); ok {
+					if v != key {
+						t.Errorf("got %q for %q", v, key)
+					}
+					got = append(got, key)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v cached, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCacheLeastRecentlyUsed(t *testing.T) {
+	c := New[int](Options{MaxEntries: 2}, nil)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Get("a")
+	c.Add("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("b wasn't evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("a was evicted though it was used after b")
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	now := time.Date(2023, 11, 3, 20, 30, 0, 0, time.UTC)
+	c := New[int](Options{TTL: time.Minute}, nil)
+	c.now = func() time.Time { return now }
+
+	c.Add("a", 1)
+	now = now.Add(59 * time.Second)
+	if _, ok := c.Get("a"); !ok {
+		t.Error("a expired early")
+	}
+	now = now.Add(time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Error("a didn't expire")
+	}
+	if c.Len() != 0 {
+		t.Errorf("got %d entries after a expired", c.Len())
+	}
+}
+
+func TestCacheDeleteFunc(t *testing.T) {
+	c := New[int](Options{}, nil)
+	for i, key := range []string{"go/1", "go/2", "htmx/1"} {
+		c.Add(key, i)
+	}
+
+	if n := c.DeleteFunc(func(key string) bool { return key[:3] == "go/" }); n != 2 {
+		t.Errorf("deleted %d entries, want 2", n)
+	}
+
+	var left []string
+	for _, key := range []string{"go/1", "go/2", "htmx/1"} {
+		if _, ok := c.Get(key); ok {
+			left = append(left, key)
+		}
+	}
+	sort.Strings(left)
+	if !reflect.DeepEqual(left, []string{"htmx/1"}) {
+		t.Errorf("got %v left", left)
+	}
+}
+
+func TestCacheGetOrLoad(t *testing.T) {
+	c := New[int](Options{}, nil)
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (int, error) {
+		loads.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make([]int, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := c.GetOrLoad(context.Background(), "a", load)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = v
+		}(i)
+	}
+	// Give the callers time to pile up on the first load.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := loads.Load(); n != 1 {
+		t.Errorf("loaded %d times, want once", n)
+	}
+	for i, v := range results {
+		if v != 42 {
+			t.Errorf("caller %d got %d", i, v)
+		}
+	}
+	if v, ok := c.Get("a"); !ok || v != 42 {
+		t.Errorf("got %d, %t cached", v, ok)
+	}
+}
+
+func TestCacheGetOrLoadError(t *testing.T) {
+	c := New[int](Options{}, nil)
+
+	errLoad := errors.New("s3 is down")
+	if _, err := c.GetOrLoad(context.Background(), "a", func(context.Context) (int, error) { return 0, errLoad }); !errors.Is(err, errLoad) {
+		t.Errorf("got error %v, want %v", err, errLoad)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("the failed load was cached")
+	}
+
+	v, err := c.GetOrLoad(context.Background(), "a", func(context.Context) (int, error) { return 1, nil })
+	if err != nil || v != 1 {
+		t.Errorf("got %d, %v after the failed load", v, err)
+	}
+}
+
+func TestCacheGetOrLoadCancelled(t *testing.T) {
+	c := New[int](Options{}, nil)
+
+	release := make(chan struct{})
+	loadErr := make(chan error, 1)
+	load := func(ctx context.Context) (int, error) {
+		<-release
+		loadErr <- ctx.Err()
+		return 42, nil
+	}
+
+	// The first caller gives up while the load it started is running.
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := c.GetOrLoad(ctx, "a", load)
+		first <- err
+	}()
+	second := make(chan int)
+	go func() {
+		v, _ := c.GetOrLoad(context.Background(), "a", load)
+		second <- v
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v for the cancelled caller", err)
+	}
+	close(release)
+	if v := <-second; v != 42 {
+		t.Errorf("got %d for the other caller", v)
+	}
+	if err := <-loadErr; err != nil {
+		t.Errorf("the load was cancelled with its caller: %v", err)
+	}
+}
+
+func TestCacheGetOrLoadTimeout(t *testing.T) {
+	c := New[int](Options{LoadTimeout: 10 * time.Millisecond}, nil)
+
+	_, err := c.GetOrLoad(context.Background(), "a", func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want the load to time out", err)
+	}
+}
+
+func TestGroupPanic(t *testing.T) {
+	var g Group[int]
+
+	_, err := g.Do(context.Background(), "a", func(context.Context) (int, error) {
+		panic("boom")
+	})
+	if err == nil {
+		t.Fatal("got no error from the panicked call")
+	}
+
+	v, err := g.Do(context.Background(), "a", func(context.Context) (int, error) { return 1, nil })
+	if err != nil || v != 1 {
+		t.Errorf("got %d, %v after the panicked call", v, err)
+	}
+}
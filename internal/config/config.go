@@ -0,0 +1,412 @@
+// Package config loads the website configuration.
+//
+// Values are read from the defaults, then the optional TOML file pointed to by CONFIG_FILE,
+// then the environment, each overriding the last.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/warrenb95/website/internal/highlight"
+	"github.com/warrenb95/website/internal/sanitize"
+	"github.com/warrenb95/website/internal/transform"
+)
+
+const (
+	StorageAWS   = "aws"
+	StorageLocal = "local"
+
+	EnvProduction  = "PRODUCTION"
+	EnvDevelopment = "DEVELOPMENT"
+)
+
+type Config struct {
+	// Env is the environment the site is running in, e.g. PRODUCTION or DEVELOPMENT.
+	Env string `toml:"env"`
+	// Port is the port to listen on, AWS Elastic Beanstalk runs off port 5000.
+	Port string `toml:"port"`
+	// SiteURL is the public URL of the site used in absolute links, e.g. canonical URLs.
+	// It's required in production, elsewhere the request's host is used when it's empty.
+	SiteURL string `toml:"site_url"`
+	// LogFile is where the logs are saved in production.
+	LogFile string `toml:"log_file"`
+	// ViewsDir is where the templates are read from in development, they're embedded otherwise.
+	ViewsDir string `toml:"views_dir"`
+	// Storage selects the blog backend, either "aws" or "local".
+	Storage string `toml:"storage"`
+	// AdminToken is the bearer token for the admin API, which is off without one.
+	AdminToken string `toml:"admin_token"`
+
+	Local     Local     `toml:"local"`
+	AWS       AWS       `toml:"aws"`
+	Robots    Robots    `toml:"robots"`
+	Highlight Highlight `toml:"highlight"`
+	TOC       TOC       `toml:"toc"`
+	Markdown  Markdown  `toml:"markdown"`
+	Sanitize  Sanitize  `toml:"sanitize"`
+	Cache     Cache     `toml:"cache"`
+	Index     Index     `toml:"index"`
+}
+
+// Local configures the local filesystem storage.
+type Local struct {
+	ContentDir string `toml:"content_dir"`
+}
+
+// AWS configures the DynamoDB and S3 storage.
+type AWS struct {
+	Region string `toml:"region"`
+	// EndpointURL overrides the AWS endpoint, e.g. for LocalStack or DynamoDB Local.
+	EndpointURL string `toml:"endpoint_url"`
+	Table       string `toml:"table"`
+	Bucket      string `toml:"bucket"`
+	KeyPrefix   string `toml:"key_prefix"`
+}
+
+// Robots configures which crawlers robots.txt lets in.
+type Robots struct {
+	// Disallow are the paths no crawler should crawl.
+	Disallow []string `toml:"disallow"`
+	// Allow are the only crawlers allowed in when it's set, e.g. Googlebot.
+	Allow []string `toml:"allow"`
+	// Block are the crawlers kept out of the whole site.
+	Block []string `toml:"block"`
+	// BlockAITraining keeps out the crawlers collecting AI training data.
+	BlockAITraining bool `toml:"block_ai_training"`
+}
+
+// Highlight configures how code blocks are highlighted.
+type Highlight struct {
+	// Theme is the chroma theme, see https://xyproto.github.io/splash/docs/.
+	Theme string `toml:"theme"`
+	// LineNumbers numbers the lines of every code block, blocks can override it with linenos.
+	LineNumbers bool `toml:"line_numbers"`
+}
+
+// TOC configures the tables of contents shown at the top of blogs.
+type TOC struct {
+	// Depth is how many levels of headings are listed, from the blog's highest level ones.
+	Depth int `toml:"depth"`
+	// MinHeadings is how many headings a blog needs for its table of contents to be shown,
+	// unless its front matter's toc says otherwise.
+	MinHeadings int `toml:"min_headings"`
+}
+
+// Markdown configures how blogs' markdown is rendered.
+type Markdown struct {
+	// Transformers are the transformers run over the rendered HTML, in order, e.g. "heading-anchors".
+	Transformers []string `toml:"transformers"`
+}
+
+// Sanitize configures what's let through of the HTML blogs render to, so raw HTML in guest posts
+// can't run script. Everything else is removed.
+type Sanitize struct {
+	// Elements are the elements kept, e.g. "table".
+	Elements []string `toml:"elements"`
+	// Attributes are the attributes kept on every element like "class", or on one like "a.href".
+	Attributes []string `toml:"attributes"`
+	// Schemes are the URL schemes links and images can use, relative URLs always can.
+	Schemes []string `toml:"schemes"`
+	// Trusted is for when every author is trusted, it lets through iframes from IframeHosts.
+	Trusted     bool     `toml:"trusted"`
+	IframeHosts []string `toml:"iframe_hosts"`
+}
+
+// Cache bounds the in-memory cache of rendered blogs, 0 is no bound.
+type Cache struct {
+	// MaxEntries is how many rendered blogs are kept.
+	MaxEntries int `toml:"max_entries"`
+	// MaxBytes is how big the rendered blogs' HTML and markdown can be in all.
+	MaxBytes int `toml:"max_bytes"`
+	// TTL is how long a rendered blog is kept, blogs are rendered again sooner when their markdown changes.
+	TTL time.Duration `toml:"ttl"`
+}
+
+// Index configures the in-memory index of the blogs' content that search and related blogs use.
+type Index struct {
+	// Refresh is how often it's rebuilt, with the blogs whose markdown changed rendered again.
+	Refresh time.Duration `toml:"refresh"`
+}
+
+// Default returns the configuration the production site runs with.
+func Default() Config {
+	policy := sanitize.DefaultPolicy()
+
+	return Config{
+		Port:     "5000",
+		LogFile:  "/var/log/blog.log",
+		ViewsDir: "views",
+		Storage:  StorageAWS,
+		Local: Local{
+			ContentDir: "content",
+		},
+		AWS: AWS{
+			Region:    "eu-west-2",
+			Table:     "blogs",
+			Bucket:    "warrenb95-blog",
+			KeyPrefix: "blogs/",
+		},
+		Robots: Robots{
+			// The htmx fragments and the API aren't pages worth indexing.
+			Disallow: []string{"/blogs", "/search", "/api/"},
+		},
+		Highlight: Highlight{
+			Theme: "monokai",
+		},
+		TOC: TOC{
+			Depth:       3,
+			MinHeadings: 3,
+		},
+		Markdown: Markdown{
+			Transformers: append([]string(nil), transform.Defaults...),
+		},
+		Sanitize: Sanitize{
+			Elements:   policy.Elements,
+			Attributes: policy.Attributes,
+			Schemes:    policy.Schemes,
+		},
+		Cache: Cache{
+			MaxEntries: 256,
+			MaxBytes:   32 << 20,
+			TTL:        time.Hour,
+		},
+		Index: Index{
+			Refresh: 5 * time.Minute,
+		},
+	}
+}
+
+// Load reads the configuration from CONFIG_FILE and the environment and validates it.
+func Load() (Config, error) {
+	return load(os.LookupEnv)
+}
+
+func load(lookup func(string) (string, bool)) (Config, error) {
+	cfg := Default()
+
+	if path, ok := lookup("CONFIG_FILE"); ok && path != "" {
+		if _, err := toml.DecodeFile(path, &cfg); err != nil {
+			return cfg, fmt.Errorf("reading config file %s: %w", path, err)
+		}
+	}
+
+	for name, field := range map[string]*string{
+		"ENV":              &cfg.Env,
+		"PORT":             &cfg.Port,
+		"SITE_URL":         &cfg.SiteURL,
+		"LOG_FILE":         &cfg.LogFile,
+		"VIEWS_DIR":        &cfg.ViewsDir,
+		"STORAGE":          &cfg.Storage,
+		"CONTENT_DIR":      &cfg.Local.ContentDir,
+		"AWS_REGION":       &cfg.AWS.Region,
+		"AWS_ENDPOINT_URL": &cfg.AWS.EndpointURL,
+		"BLOG_TABLE":       &cfg.AWS.Table,
+		"BLOG_BUCKET":      &cfg.AWS.Bucket,
+		"BLOG_KEY_PREFIX":  &cfg.AWS.KeyPrefix,
+		"HIGHLIGHT_THEME":  &cfg.Highlight.Theme,
+		"ADMIN_TOKEN":      &cfg.AdminToken,
+	} {
+		if v, ok := lookup(name); ok {
+			*field = v
+		}
+	}
+
+	// Lists are comma separated in the environment.
+	for name, field := range map[string]*[]string{
+		"ROBOTS_DISALLOW":       &cfg.Robots.Disallow,
+		"ROBOTS_ALLOW":          &cfg.Robots.Allow,
+		"ROBOTS_BLOCK":          &cfg.Robots.Block,
+		"MARKDOWN_TRANSFORMERS": &cfg.Markdown.Transformers,
+		"SANITIZE_ELEMENTS":     &cfg.Sanitize.Elements,
+		"SANITIZE_ATTRIBUTES":   &cfg.Sanitize.Attributes,
+		"SANITIZE_SCHEMES":      &cfg.Sanitize.Schemes,
+		"SANITIZE_IFRAME_HOSTS": &cfg.Sanitize.IframeHosts,
+	} {
+		if v, ok := lookup(name); ok {
+			*field = splitList(v)
+		}
+	}
+
+	for name, field := range map[string]*bool{
+		"ROBOTS_BLOCK_AI_TRAINING": &cfg.Robots.BlockAITraining,
+		"HIGHLIGHT_LINE_NUMBERS":   &cfg.Highlight.LineNumbers,
+		"SANITIZE_TRUSTED":         &cfg.Sanitize.Trusted,
+	} {
+		if v, ok := lookup(name); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid config: %s %q must be true or false", name, v)
+			}
+			*field = b
+		}
+	}
+
+	for name, field := range map[string]*int{
+		"TOC_DEPTH":         &cfg.TOC.Depth,
+		"TOC_MIN_HEADINGS":  &cfg.TOC.MinHeadings,
+		"CACHE_MAX_ENTRIES": &cfg.Cache.MaxEntries,
+		"CACHE_MAX_BYTES":   &cfg.Cache.MaxBytes,
+	} {
+		if v, ok := lookup(name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid config: %s %q must be a number", name, v)
+			}
+			*field = n
+		}
+	}
+
+	for name, field := range map[string]*time.Duration{
+		"CACHE_TTL":     &cfg.Cache.TTL,
+		"INDEX_REFRESH": &cfg.Index.Refresh,
+	} {
+		if v, ok := lookup(name); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid config: %s %q must be a duration like 1h30m", name, v)
+			}
+			*field = d
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// Validate reports every invalid value at once so they can all be fixed before the next start.
+func (c Config) Validate() error {
+	var errs []error
+
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("port %q must be a number between 1 and 65535", c.Port))
+	}
+	if c.SiteURL != "" {
+		if u, err := url.Parse(c.SiteURL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("site_url %q must be an absolute URL", c.SiteURL))
+		}
+	}
+	// The request's Host header is up to the client, so it can't be trusted for canonical links.
+	if c.IsProduction() && c.SiteURL == "" {
+		errs = append(errs, errors.New("site_url is required in production"))
+	}
+	if c.IsProduction() && c.LogFile == "" {
+		errs = append(errs, errors.New("log_file is required in production"))
+	}
+	if c.IsDevelopment() && c.ViewsDir == "" {
+		errs = append(errs, errors.New("views_dir is required in development"))
+	}
+
+	for _, path := range c.Robots.Disallow {
+		if !strings.HasPrefix(path, "/") {
+			errs = append(errs, fmt.Errorf("robots.disallow path %q must start with /", path))
+		}
+	}
+
+	if !highlight.ValidTheme(c.Highlight.Theme) {
+		errs = append(errs, fmt.Errorf("highlight.theme %q isn't a chroma theme", c.Highlight.Theme))
+	}
+
+	if c.TOC.Depth < 1 || c.TOC.Depth > 6 {
+		errs = append(errs, fmt.Errorf("toc.depth %d must be between 1 and 6", c.TOC.Depth))
+	}
+	if c.TOC.MinHeadings < 0 {
+		errs = append(errs, fmt.Errorf("toc.min_headings %d can't be negative", c.TOC.MinHeadings))
+	}
+
+	for _, name := range c.Markdown.Transformers {
+		if !transform.Registered(name) {
+			errs = append(errs, fmt.Errorf("markdown.transformers %q isn't a transformer, there's %s", name, strings.Join(transform.Names(), ", ")))
+		}
+	}
+
+	for _, scheme := range c.Sanitize.Schemes {
+		if u, err := url.Parse(scheme + ":"); err != nil || u.Scheme != strings.ToLower(scheme) {
+			errs = append(errs, fmt.Errorf("sanitize.schemes %q isn't a URL scheme", scheme))
+		} else if u.Scheme == "javascript" || u.Scheme == "vbscript" {
+			errs = append(errs, fmt.Errorf("sanitize.schemes %q runs script", scheme))
+		}
+	}
+	for _, host := range c.Sanitize.IframeHosts {
+		if host == "" || strings.ContainsAny(host, ":/") {
+			errs = append(errs, fmt.Errorf("sanitize.iframe_hosts %q must be a host name like www.youtube-nocookie.com", host))
+		}
+	}
+
+	if c.Cache.MaxEntries < 0 {
+		errs = append(errs, fmt.Errorf("cache.max_entries %d can't be negative", c.Cache.MaxEntries))
+	}
+	if c.Cache.MaxBytes < 0 {
+		errs = append(errs, fmt.Errorf("cache.max_bytes %d can't be negative", c.Cache.MaxBytes))
+	}
+	if c.Cache.TTL < 0 {
+		errs = append(errs, fmt.Errorf("cache.ttl %s can't be negative", c.Cache.TTL))
+	}
+	if c.Index.Refresh <= 0 {
+		errs = append(errs, fmt.Errorf("index.refresh %s must be positive", c.Index.Refresh))
+	}
+
+	// It's compared in constant time, but a short one can still be guessed.
+	if c.AdminToken != "" && len(c.AdminToken) < 16 {
+		errs = append(errs, errors.New("admin_token must be at least 16 characters"))
+	}
+
+	switch c.Storage {
+	case StorageLocal:
+		if c.Local.ContentDir == "" {
+			errs = append(errs, errors.New("local.content_dir is required for local storage"))
+		}
+	case StorageAWS:
+		if c.AWS.Region == "" {
+			errs = append(errs, errors.New("aws.region is required for aws storage"))
+		}
+		if c.AWS.Table == "" {
+			errs = append(errs, errors.New("aws.table is required for aws storage"))
+		}
+		if c.AWS.Bucket == "" {
+			errs = append(errs, errors.New("aws.bucket is required for aws storage"))
+		}
+		if c.AWS.EndpointURL != "" {
+			if u, err := url.Parse(c.AWS.EndpointURL); err != nil || u.Scheme == "" || u.Host == "" {
+				errs = append(errs, fmt.Errorf("aws.endpoint_url %q must be an absolute URL", c.AWS.EndpointURL))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("storage %q must be %q or %q", c.Storage, StorageAWS, StorageLocal))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func (c Config) IsProduction() bool {
+	return c.Env == EnvProduction
+}
+
+func (c Config) IsDevelopment() bool {
+	return c.Env == EnvDevelopment
+}
+
+// splitList splits a comma separated list, dropping empty items.
+func splitList(s string) []string {
+	list := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.toml")
+	err := os.WriteFile(file, []byte(`
+port = "8080"
+
+[aws]
+region = "us-east-1"
+endpoint_url = "http://localhost:4566"
+table = "staging-blogs"
+
+[robots]
+block = ["CCBot"]
+block_ai_training = true
+
+[cache]
+ttl = "30m"
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		env     map[string]string
+		want    func(*Config)
+		wantErr []string
+	}{
+		"defaults": {
+			want: func(*Config) {},
+		},
+		"config file": {
+			env: map[string]string{"CONFIG_FILE": file},
+			want: func(c *Config) {
+				c.Port = "8080"
+				c.AWS.Region = "us-east-1"
+				c.AWS.EndpointURL = "http://localhost:4566"
+				c.AWS.Table = "staging-blogs"
+				c.Robots.Block = []string{"CCBot"}
+				c.Robots.BlockAITraining = true
+				c.Cache.TTL = 30 * time.Minute
+			},
+		},
+		"environment overrides config file": {
+			env: map[string]string{
+				"CONFIG_FILE": file,
+				"PORT":        "9000",
+				"BLOG_BUCKET": "staging-bucket",
+				"STORAGE":     "local",
+				"CONTENT_DIR": "drafts",
+
+				"ROBOTS_ALLOW":             "Googlebot, Bingbot,",
+				"ROBOTS_BLOCK":             "",
+				"ROBOTS_BLOCK_AI_TRAINING": "false",
+
+				"HIGHLIGHT_THEME":        "dracula",
+				"HIGHLIGHT_LINE_NUMBERS": "true",
+				"TOC_DEPTH":              "2",
+
+				"MARKDOWN_TRANSFORMERS": "heading-anchors, tables",
+				"SANITIZE_TRUSTED":      "true",
+				"SANITIZE_IFRAME_HOSTS": "www.youtube-nocookie.com",
+
+				"CACHE_MAX_ENTRIES": "10",
+				"CACHE_TTL":         "5m",
+				"INDEX_REFRESH":     "1m",
+			},
+			want: func(c *Config) {
+				c.Port = "9000"
+				c.Storage = StorageLocal
+				c.Local.ContentDir = "drafts"
+				c.AWS.Region = "us-east-1"
+				c.AWS.EndpointURL = "http://localhost:4566"
+				c.AWS.Table = "staging-blogs"
+				c.AWS.Bucket = "staging-bucket"
+				c.Robots.Allow = []string{"Googlebot", "Bingbot"}
+				c.Robots.Block = []string{}
+				c.Highlight = Highlight{Theme: "dracula", LineNumbers: true}
+				c.TOC.Depth = 2
+				c.Markdown.Transformers = []string{"heading-anchors", "tables"}
+				c.Sanitize.Trusted = true
+				c.Sanitize.IframeHosts = []string{"www.youtube-nocookie.com"}
+				c.Cache.MaxEntries = 10
+				c.Cache.TTL = 5 * time.Minute
+				c.Index.Refresh = time.Minute
+			},
+		},
+		"missing config file": {
+			env:     map[string]string{"CONFIG_FILE": "does-not-exist.toml"},
+			wantErr: []string{"reading config file does-not-exist.toml"},
+		},
+		"invalid values": {
+			env: map[string]string{
+				"PORT":             "http",
+				"BLOG_TABLE":       "",
+				"AWS_ENDPOINT_URL": "localhost",
+				"SITE_URL":         "warrenb95.dev",
+			},
+			wantErr: []string{
+				`site_url "warrenb95.dev" must be an absolute URL`,
+				`port "http" must be a number`,
+				"aws.table is required",
+				`aws.endpoint_url "localhost" must be an absolute URL`,
+			},
+		},
+		"production": {
+			env: map[string]string{"ENV": "PRODUCTION", "LOG_FILE": ""},
+			wantErr: []string{
+				"site_url is required in production",
+				"log_file is required in production",
+			},
+		},
+		"invalid robots": {
+			env: map[string]string{
+				"ROBOTS_DISALLOW": "/api/,drafts",
+			},
+			wantErr: []string{`robots.disallow path "drafts" must start with /`},
+		},
+		"invalid robots bool": {
+			env:     map[string]string{"ROBOTS_BLOCK_AI_TRAINING": "yes please"},
+			wantErr: []string{`ROBOTS_BLOCK_AI_TRAINING "yes please" must be true or false`},
+		},
+		"invalid toc": {
+			env:     map[string]string{"TOC_DEPTH": "7", "TOC_MIN_HEADINGS": "-1"},
+			wantErr: []string{"toc.depth 7 must be between 1 and 6", "toc.min_headings -1 can't be negative"},
+		},
+		"invalid toc number": {
+			env:     map[string]string{"TOC_DEPTH": "deep"},
+			wantErr: []string{`TOC_DEPTH "deep" must be a number`},
+		},
+		"unknown theme": {
+			env:     map[string]string{"HIGHLIGHT_THEME": "nope"},
+			wantErr: []string{`highlight.theme "nope" isn't a chroma theme`},
+		},
+		"unknown transformer": {
+			env:     map[string]string{"MARKDOWN_TRANSFORMERS": "tables,emoji"},
+			wantErr: []string{`markdown.transformers "emoji" isn't a transformer`},
+		},
+		"invalid sanitize": {
+			env: map[string]string{
+				"SANITIZE_SCHEMES":      "https,JavaScript,not a scheme",
+				"SANITIZE_IFRAME_HOSTS": "https://www.youtube.com",
+			},
+			wantErr: []string{
+				`sanitize.schemes "JavaScript" runs script`,
+				`sanitize.schemes "not a scheme" isn't a URL scheme`,
+				`sanitize.iframe_hosts "https://www.youtube.com" must be a host name`,
+			},
+		},
+		"invalid cache": {
+			env: map[string]string{
+				"CACHE_MAX_ENTRIES": "-1",
+				"CACHE_MAX_BYTES":   "-1",
+				"ADMIN_TOKEN":       "hunter2",
+			},
+			wantErr: []string{
+				"cache.max_entries -1 can't be negative",
+				"cache.max_bytes -1 can't be negative",
+				"admin_token must be at least 16 characters",
+			},
+		},
+		"invalid index refresh": {
+			env:     map[string]string{"INDEX_REFRESH": "0s"},
+			wantErr: []string{"index.refresh 0s must be positive"},
+		},
+		"invalid cache ttl": {
+			env:     map[string]string{"CACHE_TTL": "an hour"},
+			wantErr: []string{`CACHE_TTL "an hour" must be a duration`},
+		},
+		"unknown storage": {
+			env:     map[string]string{"STORAGE": "gcs"},
+			wantErr: []string{`storage "gcs" must be "aws" or "local"`},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := load(func(key string) (string, bool) {
+				v, ok := tc.env[key]
+				return v, ok
+			})
+
+			if len(tc.wantErr) > 0 {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				for _, want := range tc.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("error %q doesn't contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want := Default()
+			tc.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// Package feed writes blog feeds for feed readers.
+package feed
+
+import (
+	"time"
+)
+
+// Feed is a list of blogs in a format independent of the feed type.
+type Feed struct {
+	Title       string
+	Description string
+	Author      string
+	// Link is the page the feed is for and FeedURL is the feed itself, both absolute.
+	Link    string
+	FeedURL string
+	Updated time.Time
+	Items   []Item
+}
+
+// Item is a single blog in a feed.
+type Item struct {
+	// ID never changes for the blog, even when it's renamed.
+	ID      string
+	Title   string
+	Link    string
+	Summary string
+	// Image is the absolute URL of the blog's thumbnail.
+	Image string
+	// Content is the blog's rendered HTML.
+	Content   string
+	Published time.Time
+	Updated   time.Time
+	Tags      []string
+}
@@ -0,0 +1,144 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	published := time.Date(2023, 11, 3, 20, 0, 0, 0, time.FixedZone("", 3600))
+	return Feed{
+		Title:       "warrenb95",
+		Description: "Blogs about Go and htmx.",
+		Author:      "warrenb95",
+		Link:        "https://warrenb95.dev/",
+		FeedURL:     "https://warrenb95.dev/feed.xml",
+		Updated:     published.Add(time.Hour),
+		Items: []Item{{
+			ID:        "https://warrenb95.dev/blog/htmx_and_go",
+			Title:     "htmx & Go",
+			Link:      "https://warrenb95.dev/blog/htmx_and_go",
+			Summary:   "Using htmx with Go.",
+			Image:     "https://warrenb95.dev/static/htmx.png",
+			Content:   `<p>Some <a href="https://warrenb95.dev/about">html</a></p>`,
+			Published: published,
+			Updated:   published.Add(time.Hour),
+			Tags:      []string{"go", "htmx"},
+		}},
+	}
+}
+
+func TestWriteRSS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testFeed().WriteRSS(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		xml.Header,
+		`<rss version="2.0"`,
+		`<atom:link href="https://warrenb95.dev/feed.xml" rel="self" type="application/rss+xml"></atom:link>`,
+		"<title>htmx &amp; Go</title>",
+		`<guid isPermaLink="true">https://warrenb95.dev/blog/htmx_and_go</guid>`,
+		"<pubDate>Fri, 03 Nov 2023 20:00:00 +0100</pubDate>",
+		"<lastBuildDate>Fri, 03 Nov 2023 21:00:00 +0100</lastBuildDate>",
+		"<description>Using htmx with Go.</description>",
+		"<content:encoded>&lt;p&gt;Some &lt;a href=&#34;https://warrenb95.dev/about&#34;&gt;html&lt;/a&gt;&lt;/p&gt;</content:encoded>",
+		"<category>go</category>",
+		"<category>htmx</category>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("rss doesn't contain %q\n%s", want, out)
+		}
+	}
+
+	var parsed struct {
+		Items []struct {
+			Content string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+		} `xml:"channel>item"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("rss isn't valid xml: %v", err)
+	}
+	if len(parsed.Items) != 1 || parsed.Items[0].Content != testFeed().Items[0].Content {
+		t.Errorf("parsed items = %+v", parsed.Items)
+	}
+}
+
+func TestWriteAtom(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testFeed().WriteAtom(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom">`,
+		`<link href="https://warrenb95.dev/feed.xml" rel="self" type="application/atom+xml"></link>`,
+		"<updated>2023-11-03T21:00:00+01:00</updated>",
+		"<author>\n    <name>warrenb95</name>",
+		"<published>2023-11-03T20:00:00+01:00</published>",
+		`<summary type="text">Using htmx with Go.</summary>`,
+		`<content type="html">&lt;p&gt;Some`,
+		`<category term="go"></category>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("atom doesn't contain %q\n%s", want, out)
+		}
+	}
+
+	var parsed struct {
+		Entries []struct {
+			ID      string `xml:"id"`
+			Content string `xml:"content"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("atom isn't valid xml: %v", err)
+	}
+	want := testFeed().Items[0]
+	if len(parsed.Entries) != 1 || parsed.Entries[0].ID != want.ID || parsed.Entries[0].Content != want.Content {
+		t.Errorf("parsed entries = %+v", parsed.Entries)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testFeed().WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("feed isn't valid json: %v", err)
+	}
+
+	want := map[string]any{
+		"version":       "https://jsonfeed.org/version/1.1",
+		"title":         "warrenb95",
+		"home_page_url": "https://warrenb95.dev/",
+		"feed_url":      "https://warrenb95.dev/feed.xml",
+		"description":   "Blogs about Go and htmx.",
+		"authors":       []any{map[string]any{"name": "warrenb95"}},
+		"items": []any{map[string]any{
+			"id":             "https://warrenb95.dev/blog/htmx_and_go",
+			"url":            "https://warrenb95.dev/blog/htmx_and_go",
+			"title":          "htmx & Go",
+			"content_html":   `<p>Some <a href="https://warrenb95.dev/about">html</a></p>`,
+			"summary":        "Using htmx with Go.",
+			"image":          "https://warrenb95.dev/static/htmx.png",
+			"date_published": "2023-11-03T20:00:00+01:00",
+			"date_modified":  "2023-11-03T21:00:00+01:00",
+			"tags":           []any{"go", "htmx"},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v\nwant %v", got, want)
+	}
+}
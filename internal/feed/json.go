@@ -0,0 +1,79 @@
+package feed
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONContentType is the media type of JSON feeds.
+const JSONContentType = "application/feed+json; charset=utf-8"
+
+type jsonFeed struct {
+	Version     string       `json:"version"`
+	Title       string       `json:"title"`
+	HomePageURL string       `json:"home_page_url,omitempty"`
+	FeedURL     string       `json:"feed_url,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Authors     []jsonAuthor `json:"authors,omitempty"`
+	Items       []jsonItem   `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url,omitempty"`
+	Title         string   `json:"title,omitempty"`
+	ContentHTML   string   `json:"content_html,omitempty"`
+	ContentText   string   `json:"content_text,omitempty"`
+	Summary       string   `json:"summary,omitempty"`
+	Image         string   `json:"image,omitempty"`
+	DatePublished string   `json:"date_published,omitempty"`
+	DateModified  string   `json:"date_modified,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// WriteJSON writes the feed as JSON Feed 1.1.
+func (f Feed) WriteJSON(w io.Writer) error {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Items:       []jsonItem{},
+	}
+	if f.Author != "" {
+		feed.Authors = []jsonAuthor{{Name: f.Author}}
+	}
+	for _, item := range f.Items {
+		ji := jsonItem{
+			ID:            item.ID,
+			URL:           item.Link,
+			Title:         item.Title,
+			ContentHTML:   item.Content,
+			Summary:       item.Summary,
+			Image:         item.Image,
+			DatePublished: rfc3339(item.Published),
+			Tags:          item.Tags,
+		}
+		if !item.Updated.Equal(item.Published) {
+			ji.DateModified = rfc3339(item.Updated)
+		}
+		if ji.ContentHTML == "" {
+			// Every item needs content, the summary will do when it couldn't be rendered.
+			ji.ContentText = item.Summary
+		}
+		feed.Items = append(feed.Items, ji)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		return fmt.Errorf("encoding json feed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Package highlight renders code blocks as HTML with chroma, styled by CSS classes rather than
+// inline styles so the theme is one stylesheet.
+package highlight
+
+import (
+	"fmt"
+	"html"
+	"io"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// tabWidth is how many spaces a tab is shown as.
+const tabWidth = 4
+
+// Highlighter renders code blocks in a theme.
+type Highlighter struct {
+	style *chroma.Style
+	// lineNumbers is whether code blocks have line numbers unless their info string says otherwise.
+	lineNumbers bool
+}
+
+// New returns a Highlighter for the chroma theme, see https://xyproto.github.io/splash/docs/.
+// Unknown themes fall back to chroma's fallback theme, check them with ValidTheme.
+func New(theme string, lineNumbers bool) *Highlighter {
+	return &Highlighter{
+		style:       styles.Get(theme),
+		lineNumbers: lineNumbers,
+	}
+}
+
+// ValidTheme reports whether the chroma theme exists.
+func ValidTheme(theme string) bool {
+	_, ok := styles.Registry[theme]
+	return ok
+}
+
+// layoutCSS lays out the code blocks and their titles whatever the theme.
+const layoutCSS = `.chroma { padding: 1em; border-radius: 0.375rem; overflow-x: auto; }
+.chroma .lntable .chroma { padding: 0; }
+.code-block { margin: 0 0 1rem; }
+.code-title + .chroma { border-top-left-radius: 0; border-top-right-radius: 0; }
+.code-title { font-family: monospace; font-size: 0.875em; padding: 0.25em 1em; background: rgba(255, 255, 255, 0.1); border-radius: 0.375rem 0.375rem 0 0; }
+`
+
+// CSS writes the theme's stylesheet for the highlighted code blocks.
+func (h *Highlighter) CSS(w io.Writer) error {
+	if err := h.formatter(Info{}).WriteCSS(w, h.style); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, layoutCSS)
+	return err
+}
+
+// Block writes the code highlighted as the info string's language, in a <figure> captioned with
+// its title if it has one.
+func (h *Highlighter) Block(w io.Writer, code string, info Info) error {
+	lexer := lexers.Get(info.Language)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	tokens, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return fmt.Errorf("tokenising %s code: %w", lexer.Config().Name, err)
+	}
+
+	if info.Title != "" {
+		fmt.Fprintf(w, `<figure class="code-block"><figcaption class="code-title">%s</figcaption>`, html.EscapeString(info.Title))
+	}
+	if err := h.formatter(info).Format(w, h.style, tokens); err != nil {
+		return fmt.Errorf("formatting %s code: %w", lexer.Config().Name, err)
+	}
+	if info.Title != "" {
+		io.WriteString(w, "</figure>")
+	}
+
+	return nil
+}
+
+func (h *Highlighter) formatter(info Info) *chromahtml.Formatter {
+	lineNumbers := h.lineNumbers
+	if info.LineNumbers != nil {
+		lineNumbers = *info.LineNumbers
+	}
+
+	return chromahtml.New(
+		chromahtml.WithClasses(true),
+		chromahtml.TabWidth(tabWidth),
+		chromahtml.WithLineNumbers(lineNumbers),
+		// Numbers in their own column aren't copied with the code.
+		chromahtml.LineNumbersInTable(true),
+		chromahtml.HighlightLines(info.Lines),
+	)
+}
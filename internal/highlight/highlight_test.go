@@ -0,0 +1,84 @@
+package highlight
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBlock(t *testing.T) {
+	code := "package main\n\nfunc main() {\n\tprintln(\"<hi>\")\n}\n"
+	yes := true
+
+	tests := map[string]struct {
+		lineNumbers bool
+		info        Info
+		wantInHTML  []string
+		notInHTML   []string
+	}{
+		"language": {
+			info: Info{Language: "go"},
+			wantInHTML: []string{
+				`<pre class="chroma">`,
+				`<span class="kn">package</span>`,
+				`<span class="s">&#34;&lt;hi&gt;&#34;</span>`,
+			},
+			notInHTML: []string{"style=", "<figure", "lnt"},
+		},
+		"unknown language": {
+			info:       Info{Language: "nope"},
+			wantInHTML: []string{`<pre class="chroma">`, "package main"},
+			notInHTML:  []string{`class="kn"`},
+		},
+		"highlighted lines": {
+			info:       Info{Language: "go", Lines: [][2]int{{3, 3}}},
+			wantInHTML: []string{`<span class="line hl"><span class="cl"><span class="kd">func</span>`},
+		},
+		"title": {
+			info:       Info{Language: "go", Title: "<main>.go"},
+			wantInHTML: []string{`<figure class="code-block"><figcaption class="code-title">&lt;main&gt;.go</figcaption><pre class="chroma">`, "</figure>"},
+		},
+		"line numbers": {
+			lineNumbers: true,
+			info:        Info{Language: "go"},
+			wantInHTML:  []string{`<table class="lntable">`, "<span class=\"lnt\">5\n</span>"},
+		},
+		"line numbers from the info": {
+			info:       Info{Language: "go", LineNumbers: &yes},
+			wantInHTML: []string{"<span class=\"lnt\">5\n</span>"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := New("monokai", tc.lineNumbers).Block(&sb, code, tc.info); err != nil {
+				t.Fatal(err)
+			}
+			html := sb.String()
+			for _, want := range tc.wantInHTML {
+				if !strings.Contains(html, want) {
+					t.Errorf("html doesn't contain %q\n%s", want, html)
+				}
+			}
+			for _, unwanted := range tc.notInHTML {
+				if strings.Contains(html, unwanted) {
+					t.Errorf("html contains %q\n%s", unwanted, html)
+				}
+			}
+		})
+	}
+}
+
+func TestCSS(t *testing.T) {
+	var sb strings.Builder
+	if err := New("monokai", false).CSS(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if css := sb.String(); !strings.Contains(css, ".chroma .kn {") || !strings.Contains(css, ".chroma .hl {") {
+		t.Errorf("css doesn't style keywords and highlighted lines\n%s", css)
+	}
+
+	if !ValidTheme("monokai") || ValidTheme("nope") {
+		t.Error("ValidTheme doesn't know monokai from nope")
+	}
+}
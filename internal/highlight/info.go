@@ -0,0 +1,89 @@
+package highlight
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Info is what a fenced code block's info string asks for, e.g. go {3-5,8} title="main.go" linenos.
+type Info struct {
+	// Language is the first word, a language name, alias or file extension chroma knows.
+	Language string
+	// Lines are the inclusive ranges of lines to highlight, counting from 1.
+	Lines [][2]int
+	// Title captions the block, usually a file name. filename= works too.
+	Title string
+	// LineNumbers overrides whether the block has line numbers, with linenos or linenos=false.
+	LineNumbers *bool
+}
+
+// ParseInfo parses a fenced code block's info string. Anything it doesn't understand is ignored.
+func ParseInfo(s string) Info {
+	var info Info
+	for i, field := range fields(s) {
+		key, value, hasValue := strings.Cut(field, "=")
+		switch {
+		case strings.HasPrefix(field, "{") && strings.HasSuffix(field, "}"):
+			info.Lines = append(info.Lines, parseLines(field[1:len(field)-1])...)
+		case key == "title" || key == "filename":
+			info.Title = strings.Trim(value, `"'`)
+		case key == "linenos":
+			show := true
+			if hasValue {
+				show, _ = strconv.ParseBool(value)
+			}
+			info.LineNumbers = &show
+		case i == 0 && !hasValue:
+			info.Language = strings.ToLower(field)
+		}
+	}
+	return info
+}
+
+// fields splits s on spaces outside of quotes.
+func fields(s string) []string {
+	var out []string
+	var field strings.Builder
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ' ' || r == '\t':
+			if field.Len() > 0 {
+				out = append(out, field.String())
+				field.Reset()
+			}
+			continue
+		}
+		field.WriteRune(r)
+	}
+	if field.Len() > 0 {
+		out = append(out, field.String())
+	}
+	return out
+}
+
+// parseLines parses line ranges like 3-5,8, skipping invalid ones.
+func parseLines(s string) [][2]int {
+	var lines [][2]int
+	for _, r := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(r), "-")
+		start, err := strconv.Atoi(from)
+		if err != nil || start < 1 {
+			continue
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(to); err != nil || end < start {
+				continue
+			}
+		}
+		lines = append(lines, [2]int{start, end})
+	}
+	return lines
+}
@@ -0,0 +1,31 @@
+package highlight
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseInfo(t *testing.T) {
+	yes, no := true, false
+
+	tests := map[string]Info{
+		"":                          {},
+		"go":                        {Language: "go"},
+		"Go {3-5}":                  {Language: "go", Lines: [][2]int{{3, 5}}},
+		"go {3-5,8} {10}":           {Language: "go", Lines: [][2]int{{3, 5}, {8, 8}, {10, 10}}},
+		"go {5-3,x,0,2}":            {Language: "go", Lines: [][2]int{{2, 2}}},
+		`go title="main.go"`:        {Language: "go", Title: "main.go"},
+		`sh title="run it.sh"`:      {Language: "sh", Title: "run it.sh"},
+		"yaml filename=config.yaml": {Language: "yaml", Title: "config.yaml"},
+		"go linenos":                {Language: "go", LineNumbers: &yes},
+		"go linenos=false":          {Language: "go", LineNumbers: &no},
+		"{1} title=x.txt":           {Lines: [][2]int{{1, 1}}, Title: "x.txt"},
+		"go unknown=1 what":         {Language: "go"},
+	}
+
+	for s, want := range tests {
+		if got := ParseInfo(s); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseInfo(%q) = %+v, want %+v", s, got, want)
+		}
+	}
+}
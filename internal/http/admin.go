@@ -0,0 +1,64 @@
+package http
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warrenb95/website/internal/blog"
+)
+
+// apiPurged is the response to a cache purge.
+type apiPurged struct {
+	// Purged is how many cached renders were removed.
+	Purged int `json:"purged"`
+}
+
+// PurgeCache refreshes the content index and removes the {slug} blog's renders from the cache, or
+// every blog's without a slug, so edits to their markdown show straight away.
+func (s *Server) PurgeCache(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		if err := s.authorizeAdmin(w, r); err != nil {
+			return err
+		}
+
+		slug, ok := mux.Vars(r)["slug"]
+		if ok && !blog.ValidSlug(slug) {
+			return newError(http.StatusBadRequest, "That isn't a blog's slug.", errors.New("invalid slug"))
+		}
+		// Renders are keyed on the markdown's version in the index, so it's refreshed first. They're
+		// still removed when that fails, so they're rendered again from the markdown as it is now.
+		if _, err := s.refreshIndex(r.Context()); err != nil {
+			s.logger.WithContext(r.Context()).WithError(err).Error("Failed to refresh the content index")
+		}
+		purged := s.rendered.DeleteFunc(func(key string) bool {
+			return !ok || strings.HasPrefix(key, slug+"\x00")
+		})
+
+		s.logger.WithContext(r.Context()).
+			WithField("slug", slug).
+			WithField("purged", purged).
+			Info("Purged the rendered blog cache")
+
+		return writeJSON(w, apiPurged{Purged: purged})
+	})
+}
+
+// authorizeAdmin checks the request has the admin token as its bearer token. There's no admin API
+// without an admin token.
+func (s *Server) authorizeAdmin(w http.ResponseWriter, r *http.Request) error {
+	if s.config.AdminToken == "" {
+		return newError(http.StatusNotFound, "There's nothing here.", errors.New("no admin token configured"))
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.config.AdminToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		return newError(http.StatusUnauthorized, "A valid admin token is needed.", errors.New("invalid admin token"))
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warrenb95/website/internal/blog"
+)
+
+func TestRenderBlogCache(t *testing.T) {
+	ctx := context.Background()
+	b := blog.Blog{Slug: "cached", Uploaded: "2023-11-03T20:30:00+00:00"}
+	s, store := newTestServer(t, b)
+	content := &indexedContent{Store: store}
+	s.content = content
+
+	render := func(base string) string {
+		t.Helper()
+		b := b
+		if _, err := s.renderBlog(ctx, &b, base); err != nil {
+			t.Fatal(err)
+		}
+		return string(b.Content)
+	}
+
+	// Before it's indexed, the version comes from the content store.
+	store.PutMarkdown(ctx, "cached", []byte("first"))
+	if got := render(""); !strings.Contains(got, "first") {
+		t.Fatalf("got %q", got)
+	}
+	store.PutMarkdown(ctx, "cached", []byte("second"))
+	if got := render(""); !strings.Contains(got, "second") {
+		t.Errorf("got %q, want a render of the edited markdown", got)
+	}
+
+	// After, it's the index's until it's refreshed.
+	if _, err := s.refreshIndex(ctx); err != nil {
+		t.Fatal(err)
+	}
+	content.gets = 0
+	render("")
+	if content.gets != 0 {
+		t.Errorf("got the markdown %d times, want the cached render", content.gets)
+	}
+	store.PutMarkdown(ctx, "cached", []byte("third"))
+	if got := render(""); !strings.Contains(got, "second") {
+		t.Errorf("got %q, want the cached render until the index is refreshed", got)
+	}
+	if got := render("https://warrenb95.dev"); !strings.Contains(got, "third") {
+		t.Errorf("got %q, want a render for the other base", got)
+	}
+	if _, err := s.refreshIndex(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := render(""); !strings.Contains(got, "third") {
+		t.Errorf("got %q, want a render of the edited markdown", got)
+	}
+
+	// Without a version it's rendered every time.
+	content.fail = "fresh"
+	fresh := blog.Blog{Slug: "fresh"}
+	store.PutMarkdown(ctx, "fresh", []byte("fresh"))
+	content.gets = 0
+	for i := 0; i < 2; i++ {
+		if _, err := s.renderBlog(ctx, &fresh, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if content.gets != 2 {
+		t.Errorf("got the markdown %d times, want every render", content.gets)
+	}
+}
+
+func TestPurgeCache(t *testing.T) {
+	const token = "correct-horse-battery-staple"
+
+	tests := map[string]struct {
+		adminToken string
+		auth       string
+		vars       map[string]string
+		wantStatus int
+		wantPurged int
+		// wantFresh is whether the edits show after the purge.
+		wantFresh bool
+	}{
+		"one blog": {
+			adminToken: token,
+			auth:       "Bearer " + token,
+			vars:       map[string]string{"slug": "oldest"},
+			wantStatus: http.StatusOK,
+			wantPurged: 1,
+			wantFresh:  true,
+		},
+		"every blog": {
+			adminToken: token,
+			auth:       "Bearer " + token,
+			vars:       map[string]string{},
+			wantStatus: http.StatusOK,
+			wantPurged: 2,
+			wantFresh:  true,
+		},
+		"not cached": {
+			adminToken: token,
+			auth:       "Bearer " + token,
+			vars:       map[string]string{"slug": "middle"},
+			wantStatus: http.StatusOK,
+			wantFresh:  true,
+		},
+		"invalid slug": {
+			adminToken: token,
+			auth:       "Bearer " + token,
+			vars:       map[string]string{"slug": ".."},
+			wantStatus: http.StatusBadRequest,
+		},
+		"wrong token": {
+			adminToken: token,
+			auth:       "Bearer " + strings.ToUpper(token),
+			vars:       map[string]string{},
+			wantStatus: http.StatusUnauthorized,
+		},
+		"no token": {
+			adminToken: token,
+			vars:       map[string]string{},
+			wantStatus: http.StatusUnauthorized,
+		},
+		"admin api off": {
+			auth:       "Bearer ",
+			vars:       map[string]string{},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			s, store := newTestServer(t, apiBlogs...)
+			s.config.AdminToken = tc.adminToken
+
+			for _, slug := range []string{"oldest", "newest"} {
+				store.PutMarkdown(ctx, slug, []byte("before"))
+			}
+			if _, err := s.refreshIndex(ctx); err != nil {
+				t.Fatal(err)
+			}
+			for _, slug := range []string{"oldest", "newest"} {
+				b, _ := store.Get(ctx, slug)
+				if _, err := s.renderBlog(ctx, &b, ""); err != nil {
+					t.Fatal(err)
+				}
+				store.PutMarkdown(ctx, slug, []byte("after"))
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/cache", nil)
+			if tc.auth != "" {
+				req.Header.Set("Authorization", tc.auth)
+			}
+			req = mux.SetURLVars(req, tc.vars)
+			rec := httptest.NewRecorder()
+			s.PurgeCache(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("no WWW-Authenticate header")
+			}
+
+			if tc.wantStatus == http.StatusOK {
+				var got apiPurged
+				if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Purged != tc.wantPurged {
+					t.Errorf("purged %d, want %d", got.Purged, tc.wantPurged)
+				}
+			}
+
+			for _, slug := range []string{"oldest", "newest"} {
+				b, _ := store.Get(ctx, slug)
+				if _, err := s.renderBlog(ctx, &b, ""); err != nil {
+					t.Fatal(err)
+				}
+				if fresh := strings.Contains(string(b.Content), "after"); fresh != tc.wantFresh {
+					t.Errorf("%s rendered again = %t, want %t", slug, fresh, tc.wantFresh)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warrenb95/website/internal/blog"
+)
+
+// apiPrefix is where the JSON API is served, its errors are always sent as JSON.
+const apiPrefix = "/api/"
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// apiPost is a blog in the API. HTML and Markdown are only sent for a single post.
+type apiPost struct {
+	ID           string   `json:"id,omitempty"`
+	Slug         string   `json:"slug"`
+	Title        string   `json:"title"`
+	URL          string   `json:"url"`
+	Summary      string   `json:"summary,omitempty"`
+	Thumbnail    string   `json:"thumbnail,omitempty"`
+	Tags         []string `json:"tags"`
+	Published    string   `json:"published,omitempty"`
+	Updated      string   `json:"updated,omitempty"`
+	CanonicalURL string   `json:"canonical_url,omitempty"`
+	Draft        bool     `json:"draft,omitempty"`
+	HTML         string   `json:"html,omitempty"`
+	Markdown     string   `json:"markdown,omitempty"`
+}
+
+// apiPosts is a page of posts.
+type apiPosts struct {
+	Posts   []apiPost `json:"posts"`
+	Page    int       `json:"page"`
+	PerPage int       `json:"per_page"`
+	Total   int       `json:"total"`
+	// Next is the URL of the next page, it's empty on the last page.
+	Next string `json:"next,omitempty"`
+}
+
+// APIPosts lists the posts newest first. They can be filtered by ?tag= and uploaded ?since= and
+// ?until= dates, and paged through with ?page= and ?per_page=.
+func (s *Server) APIPosts(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		q := r.URL.Query()
+		page, err := queryInt(q, "page", 1, 0)
+		if err != nil {
+			return err
+		}
+		perPage, err := queryInt(q, "per_page", defaultPerPage, maxPerPage)
+		if err != nil {
+			return err
+		}
+		since, err := queryDate(q, "since", false)
+		if err != nil {
+			return err
+		}
+		until, err := queryDate(q, "until", true)
+		if err != nil {
+			return err
+		}
+		tag := q.Get("tag")
+
+		logger := s.logger.WithContext(r.Context())
+		filtered, err := s.listBlogs(r.Context(), logger, listQuery{Tag: tag, Since: since, Until: until})
+		if err != nil {
+			return newError(http.StatusInternalServerError, "The posts couldn't be loaded.", fmt.Errorf("listing blogs: %w", err))
+		}
+
+		data := apiPosts{Posts: []apiPost{}, Page: page, PerPage: perPage, Total: len(filtered)}
+		// Pages past the end are empty. They're compared before multiplying so huge pages can't overflow.
+		if pages := (len(filtered) + perPage - 1) / perPage; page <= pages {
+			start := (page - 1) * perPage
+			end := start + perPage
+			if end < len(filtered) {
+				q.Set("page", strconv.Itoa(page+1))
+				data.Next = s.absURL(r, r.URL.Path+"?"+q.Encode())
+			} else {
+				end = len(filtered)
+			}
+			for _, b := range filtered[start:end] {
+				data.Posts = append(data.Posts, s.apiPost(r, b))
+			}
+		}
+
+		return writeJSON(w, data)
+	})
+}
+
+// APIPost sends the {slug} post with its rendered HTML and markdown.
+func (s *Server) APIPost(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		slug := mux.Vars(r)["slug"]
+		notFound := newError(http.StatusNotFound, fmt.Sprintf("There's no post called %q.", slug), fmt.Errorf("post %q: %w", slug, blog.ErrNotFound))
+		if !blog.ValidSlug(slug) {
+			return notFound
+		}
+
+		b, err := s.blogs.Get(r.Context(), slug)
+		if errors.Is(err, blog.ErrNotFound) || (err == nil && !s.visible(b)) {
+			return notFound
+		}
+		if err != nil {
+			return fmt.Errorf("getting blog %q: %w", slug, err)
+		}
+		b.DefaultTitle()
+
+		rendered, err := s.renderBlog(r.Context(), &b, s.absURL(r, ""))
+		if err != nil {
+			return err
+		}
+
+		post := s.apiPost(r, b)
+		post.HTML = string(b.Content)
+		post.Markdown = string(rendered.Markdown)
+
+		return writeJSON(w, post)
+	})
+}
+
+func (s *Server) apiPost(r *http.Request, b blog.Blog) apiPost {
+	post := apiPost{
+		ID:           b.ID,
+		Slug:         b.Slug,
+		Title:        b.Title,
+		URL:          s.absURL(r, "/blog/"+b.Slug),
+		Summary:      b.Summary,
+		Thumbnail:    b.ThumbnailPath,
+		Tags:         b.Tags,
+		CanonicalURL: b.CanonicalURL,
+		Draft:        b.Draft,
+	}
+	if post.Tags == nil {
+		post.Tags = []string{}
+	}
+	if strings.HasPrefix(post.Thumbnail, "/") {
+		post.Thumbnail = s.absURL(r, post.Thumbnail)
+	}
+	if t, err := b.UploadedAt(); err == nil {
+		post.Published = t.Format(time.RFC3339)
+	}
+	if t, err := b.UpdatedAt(); err == nil {
+		post.Updated = t.Format(time.RFC3339)
+	}
+	return post
+}
+
+// queryInt parses the name query parameter as a positive number no bigger than max, unless max is 0.
+func queryInt(q url.Values, name string, def, max int) (int, error) {
+	v := q.Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || (max > 0 && n > max) {
+		msg := fmt.Sprintf("%s must be a positive number.", name)
+		if max > 0 {
+			msg = fmt.Sprintf("%s must be a number from 1 to %d.", name, max)
+		}
+		return 0, newError(http.StatusBadRequest, msg, fmt.Errorf("invalid %s %q", name, v))
+	}
+	return n, nil
+}
+
+// queryDate parses the name query parameter as an RFC 3339 time or a date. A date is the start
+// of the day, or the end of it when endOfDay is set so the whole day is included.
+func queryDate(q url.Values, name string, endOfDay bool) (time.Time, error) {
+	v := q.Get(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return time.Time{}, newError(http.StatusBadRequest, fmt.Sprintf("%s must be a date like 2023-11-03.", name), fmt.Errorf("invalid %s %q", name, v))
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// writeJSON sends v as JSON, encoding it first so a failure can still be sent as an error.
+func writeJSON(w http.ResponseWriter, v any) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encoding json: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err := buf.WriteTo(w)
+	return err
+}
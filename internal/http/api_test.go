@@ -0,0 +1,209 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warrenb95/website/internal/blog"
+)
+
+var apiBlogs = []blog.Blog{
+	{Slug: "oldest", Uploaded: "2023-01-05T09:00:00+00:00", Tags: []string{"go"}},
+	{Slug: "newest", Uploaded: "2023-11-03T20:30:00+00:00", Tags: []string{"go", "htmx"}},
+	{Slug: "middle", Uploaded: "2023-06-18T12:15:00+01:00", Tags: []string{"aws"}},
+	{Slug: "draft", Uploaded: "2023-12-01T09:00:00+00:00", Draft: true},
+}
+
+func TestAPIPosts(t *testing.T) {
+	tests := map[string]struct {
+		query      string
+		wantStatus int
+		wantSlugs  []string
+		wantTotal  int
+		wantNext   string
+		wantError  string
+	}{
+		"newest first": {
+			wantStatus: http.StatusOK,
+			wantSlugs:  []string{"newest", "middle", "oldest"},
+			wantTotal:  3,
+		},
+		"paginated": {
+			query:      "?per_page=2&tag=",
+			wantStatus: http.StatusOK,
+			wantSlugs:  []string{"newest", "middle"},
+			wantTotal:  3,
+			wantNext:   "http://example.com/api/v1/posts?page=2&per_page=2&tag=",
+		},
+		"last page": {
+			query:      "?per_page=2&page=2",
+			wantStatus: http.StatusOK,
+			wantSlugs:  []string{"oldest"},
+			wantTotal:  3,
+		},
+		"past the end": {
+			query:      "?page=5",
+			wantStatus: http.StatusOK,
+			wantSlugs:  []string{},
+			wantTotal:  3,
+		},
+		"huge page": {
+			query:      "?page=9223372036854775807&per_page=100",
+			wantStatus: http.StatusOK,
+			wantSlugs:  []string{},
+			wantTotal:  3,
+		},
+		"tag": {
+			query:      "?tag=Go",
+			wantStatus: http.StatusOK,
+			wantSlugs:  []string{"newest", "oldest"},
+			wantTotal:  2,
+		},
+		"dates": {
+			query:      "?since=2023-06-18&until=2023-06-18",
+			wantStatus: http.StatusOK,
+			wantSlugs:  []string{"middle"},
+			wantTotal:  1,
+		},
+		"since a time": {
+			query:      "?since=2023-06-18T12:00:00Z",
+			wantStatus: http.StatusOK,
+			wantSlugs:  []string{"newest"},
+			wantTotal:  1,
+		},
+		"invalid page": {
+			query:      "?page=0",
+			wantStatus: http.StatusBadRequest,
+			wantError:  "page must be a positive number.",
+		},
+		"too many per page": {
+			query:      "?per_page=1000",
+			wantStatus: http.StatusBadRequest,
+			wantError:  "per_page must be a number from 1 to 100.",
+		},
+		"invalid date": {
+			query:      "?since=yesterday",
+			wantStatus: http.StatusBadRequest,
+			wantError:  "since must be a date like 2023-11-03.",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, _ := newTestServer(t, apiBlogs...)
+
+			rec := httptest.NewRecorder()
+			s.APIPosts(rec, httptest.NewRequest(http.MethodGet, "/api/v1/posts"+tc.query, nil))
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("content type = %q", got)
+			}
+
+			if tc.wantError != "" {
+				var body jsonError
+				if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+				if body.Error != tc.wantError {
+					t.Errorf("error = %q, want %q", body.Error, tc.wantError)
+				}
+				return
+			}
+
+			var body apiPosts
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			slugs := []string{}
+			for _, p := range body.Posts {
+				slugs = append(slugs, p.Slug)
+			}
+			if !reflect.DeepEqual(slugs, tc.wantSlugs) {
+				t.Errorf("slugs = %v, want %v", slugs, tc.wantSlugs)
+			}
+			if body.Total != tc.wantTotal {
+				t.Errorf("total = %d, want %d", body.Total, tc.wantTotal)
+			}
+			if body.Next != tc.wantNext {
+				t.Errorf("next = %q, want %q", body.Next, tc.wantNext)
+			}
+		})
+	}
+}
+
+func TestAPIPost(t *testing.T) {
+	tests := map[string]struct {
+		slug       string
+		wantStatus int
+		want       apiPost
+	}{
+		"post": {
+			slug:       "newest",
+			wantStatus: http.StatusOK,
+			want: apiPost{
+				Slug:      "newest",
+				Title:     "Newest!",
+				URL:       "http://example.com/blog/newest",
+				Tags:      []string{"go", "htmx"},
+				Published: "2023-11-03T20:30:00Z",
+				Updated:   "2023-11-03T20:30:00Z",
+				HTML:      `<p>See <a href="http://example.com/about">about</a>.</p>` + "\n",
+				Markdown:  "See [about](/about).\n",
+			},
+		},
+		"draft": {
+			slug:       "draft",
+			wantStatus: http.StatusNotFound,
+		},
+		"missing": {
+			slug:       "does_not_exist",
+			wantStatus: http.StatusNotFound,
+		},
+		"invalid slug": {
+			slug:       "..",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, store := newTestServer(t, apiBlogs...)
+			md := "---\ntitle: Newest!\n---\nSee [about](/about).\n"
+			if err := store.PutMarkdown(context.Background(), "newest", []byte(md)); err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/posts/"+tc.slug, nil)
+			req = mux.SetURLVars(req, map[string]string{"slug": tc.slug})
+			rec := httptest.NewRecorder()
+			s.APIPost(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("content type = %q", got)
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got apiPost
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v\nwant %+v", got, tc.want)
+			}
+		})
+	}
+}
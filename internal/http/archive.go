@@ -0,0 +1,97 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warrenb95/website/internal/blog"
+)
+
+// archivePage is the blogs grouped by when they were uploaded.
+// Year and Month are set on a year's or a month's page, Years is every year on the archive's.
+type archivePage struct {
+	meta
+
+	Year  int
+	Month time.Month
+	Years []blog.ArchiveYear
+}
+
+// Archive renders the blogs uploaded in the {year} and {month}, or every blog by year without them.
+func (s *Server) Archive(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+
+		var data archivePage
+		var q listQuery
+		path := "/archive"
+		if y := vars["year"]; y != "" {
+			year, err := strconv.Atoi(y)
+			if err != nil {
+				return newError(http.StatusNotFound, "That year isn't valid.", fmt.Errorf("invalid year %q", y))
+			}
+			data.Year = year
+			q.Since = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+			q.Until = q.Since.AddDate(1, 0, 0)
+			path += fmt.Sprintf("/%d", year)
+		}
+		if m := vars["month"]; m != "" {
+			month, err := strconv.Atoi(m)
+			if err != nil || month < 1 || month > 12 {
+				return newError(http.StatusNotFound, "That month isn't valid.", fmt.Errorf("invalid month %q", m))
+			}
+			data.Month = time.Month(month)
+			q.Since = time.Date(data.Year, data.Month, 1, 0, 0, 0, 0, time.UTC)
+			q.Until = q.Since.AddDate(0, 1, 0)
+			path += fmt.Sprintf("/%02d", month)
+		}
+
+		blogs, err := s.listBlogs(r.Context(), s.logger.WithContext(r.Context()), q)
+		if err != nil {
+			return newError(http.StatusInternalServerError, "The archive couldn't be loaded.", fmt.Errorf("listing blogs: %w", err))
+		}
+		data.Years = blog.Archive(blogs)
+
+		data.Canonical = s.absURL(r, path)
+		switch {
+		case data.Month != 0:
+			data.PageTitle = fmt.Sprintf("%s %d", data.Month, data.Year)
+		case data.Year != 0:
+			data.PageTitle = strconv.Itoa(data.Year)
+		default:
+			data.PageTitle = "Archive"
+		}
+		if data.Year != 0 && len(blogs) == 0 {
+			return newError(http.StatusNotFound, fmt.Sprintf("There are no blogs from %s.", data.PageTitle), fmt.Errorf("archive %s: %w", path, blog.ErrNotFound))
+		}
+		data.Description = fmt.Sprintf("%d blogs by %s.", len(blogs), siteAuthor)
+		if data.Year != 0 {
+			data.Description = fmt.Sprintf("%d blogs by %s from %s.", len(blogs), siteAuthor, data.PageTitle)
+		}
+
+		if err := s.templates.Execute(w, "archive.html", data); err != nil {
+			return fmt.Errorf("executing archive template: %w", err)
+		}
+		return nil
+	})
+}
+
+// ArchiveWidget renders the number of blogs in each year and month, for htmx to load into the layout.
+func (s *Server) ArchiveWidget(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		blogs, err := s.listBlogs(r.Context(), s.logger.WithContext(r.Context()), listQuery{})
+		if err != nil {
+			return newError(http.StatusInternalServerError, "The archive couldn't be loaded.", fmt.Errorf("listing blogs: %w", err))
+		}
+
+		data := archivePage{Years: blog.Archive(blogs)}
+		if err := s.templates.Execute(w, "archive-widget", data); err != nil {
+			return fmt.Errorf("executing archive widget template: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warrenb95/website/internal/blog"
+)
+
+func TestArchive(t *testing.T) {
+	blogs := []blog.Blog{
+		{Slug: "jan", Title: "January", Uploaded: "2023-01-05T09:00:00+00:00"},
+		{Slug: "nov", Title: "November", Uploaded: "2023-11-03T20:30:00+00:00"},
+		{Slug: "nov_later", Title: "Later in November", Uploaded: "2023-11-20T08:00:00+00:00"},
+		{Slug: "new_year", Title: "New Year", Uploaded: "2024-01-01T12:00:00+00:00"},
+		{Slug: "draft", Title: "Draft", Uploaded: "2023-11-04T12:00:00+00:00", Draft: true},
+	}
+
+	tests := map[string]struct {
+		handler    func(*Server) http.HandlerFunc
+		vars       map[string]string
+		wantStatus int
+		wantOrder  []string
+		wantInBody []string
+		notInBody  []string
+	}{
+		"every year": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Archive },
+			wantStatus: http.StatusOK,
+			wantOrder:  []string{`href="/archive/2024"`, `href="/archive/2024/01"`, `href="/archive/2023"`, `href="/archive/2023/11"`, `href="/archive/2023/01"`},
+			wantInBody: []string{
+				"<title>Archive | 😄 warrenb95</title>",
+				`<link rel="canonical" href="http://example.com/archive" />`,
+				`class="link-primary">November</a>`,
+			},
+			notInBody: []string{`href="/blog/`},
+		},
+		"a year": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Archive },
+			vars:       map[string]string{"year": "2023"},
+			wantStatus: http.StatusOK,
+			wantOrder:  []string{`href="/blog/nov_later"`, `href="/blog/nov"`, `href="/blog/jan"`},
+			wantInBody: []string{
+				"<title>2023 | 😄 warrenb95</title>",
+				`<link rel="canonical" href="http://example.com/archive/2023" />`,
+				"Nov 20",
+			},
+			notInBody: []string{`href="/blog/new_year"`, `href="/blog/draft"`},
+		},
+		"a month": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Archive },
+			vars:       map[string]string{"year": "2023", "month": "11"},
+			wantStatus: http.StatusOK,
+			wantInBody: []string{
+				"<title>November 2023 | 😄 warrenb95</title>",
+				`<link rel="canonical" href="http://example.com/archive/2023/11" />`,
+				`href="/blog/nov"`,
+				`href="/blog/nov_later"`,
+			},
+			notInBody: []string{`href="/blog/jan"`},
+		},
+		"empty month": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Archive },
+			vars:       map[string]string{"year": "2023", "month": "06"},
+			wantStatus: http.StatusNotFound,
+			wantInBody: []string{"There are no blogs from June 2023."},
+		},
+		"invalid month": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Archive },
+			vars:       map[string]string{"year": "2023", "month": "13"},
+			wantStatus: http.StatusNotFound,
+			wantInBody: []string{"That month isn&#39;t valid."},
+		},
+		"widget": {
+			handler:    func(s *Server) http.HandlerFunc { return s.ArchiveWidget },
+			wantStatus: http.StatusOK,
+			wantOrder:  []string{`href="/archive/2024">2024 <span class="text-muted">(1)</span>`, `href="/archive/2023">2023 <span class="text-muted">(3)</span>`},
+			notInBody:  []string{"<html"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, _ := newTestServer(t, blogs...)
+
+			req := httptest.NewRequest(http.MethodGet, "/archive", nil)
+			req = mux.SetURLVars(req, tc.vars)
+			rec := httptest.NewRecorder()
+			tc.handler(s)(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			body := rec.Body.String()
+			assertOrder(t, body, tc.wantOrder)
+			for _, want := range tc.wantInBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q\n%s", want, body)
+				}
+			}
+			for _, unwanted := range tc.notInBody {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body contains %q", unwanted)
+				}
+			}
+		})
+	}
+}
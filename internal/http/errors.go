@@ -0,0 +1,140 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/warrenb95/website/internal/blog"
+)
+
+// Error is a failed request. Message is safe to show readers, Err is the cause and only logged.
+type Error struct {
+	Status  int
+	Message string
+	Err     error
+
+	// Suggestions are blogs the reader might have been looking for on a 404.
+	Suggestions []blog.Blog
+}
+
+func newError(status int, message string, err error) *Error {
+	return &Error{
+		Status:  status,
+		Message: message,
+		Err:     err,
+	}
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %v", e.Message, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// handle runs h and renders the error it returns, if any.
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, h func(http.ResponseWriter, *http.Request) error) {
+	if err := h(w, r); err != nil {
+		s.renderError(w, r, err)
+	}
+}
+
+// errorPage is the data for the 4xx.html and 5xx.html templates.
+type errorPage struct {
+	meta
+
+	Status      int
+	StatusText  string
+	Message     string
+	Suggestions []blog.Blog
+}
+
+// jsonError is the body sent to clients that accept JSON.
+type jsonError struct {
+	Status      int              `json:"status"`
+	Error       string           `json:"error"`
+	Suggestions []jsonSuggestion `json:"suggestions,omitempty"`
+}
+
+type jsonSuggestion struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// renderError logs err and sends it to the reader as a page, an htmx fragment or JSON.
+// Errors that aren't an *Error are treated as internal server errors.
+func (s *Server) renderError(w http.ResponseWriter, r *http.Request, err error) {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		appErr = newError(http.StatusInternalServerError, "Something went wrong on our end, please try again later.", err)
+	}
+
+	logger := s.logger.WithContext(r.Context()).
+		WithError(err).
+		WithField("endpoint", r.URL).
+		WithField("method", r.Method).
+		WithField("status", appErr.Status)
+	if appErr.Status >= http.StatusInternalServerError {
+		logger.Error("Request failed")
+	} else {
+		logger.Info("Request failed")
+	}
+
+	switch {
+	case strings.Contains(r.Header.Get("Accept"), "application/json") || strings.HasPrefix(r.URL.Path, apiPrefix):
+		body := jsonError{
+			Status: appErr.Status,
+			Error:  appErr.Message,
+		}
+		for _, b := range appErr.Suggestions {
+			body.Suggestions = append(body.Suggestions, jsonSuggestion{Title: b.Title, URL: "/blog/" + b.Slug})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(appErr.Status)
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			logger.WithError(err).Error("Failed to write JSON error")
+		}
+	case r.Header.Get("HX-Request") == "true":
+		// htmx swaps the response into part of the page, so don't send a whole page.
+		http.Error(w, appErr.Message, appErr.Status)
+	default:
+		s.renderErrorPage(w, appErr)
+	}
+}
+
+// renderErrorPage renders the template for the exact status if there is one, e.g. 404.html,
+// otherwise 4xx.html or 5xx.html.
+func (s *Server) renderErrorPage(w http.ResponseWriter, appErr *Error) {
+	name := fmt.Sprintf("%d.html", appErr.Status)
+	if !s.templates.Has(name) {
+		name = fmt.Sprintf("%dxx.html", appErr.Status/100)
+	}
+
+	data := errorPage{
+		meta:        meta{PageTitle: http.StatusText(appErr.Status)},
+		Status:      appErr.Status,
+		StatusText:  http.StatusText(appErr.Status),
+		Message:     appErr.Message,
+		Suggestions: appErr.Suggestions,
+	}
+
+	var b bytes.Buffer
+	if err := s.templates.Execute(&b, name, data); err != nil {
+		s.logger.WithError(err).WithField("template", name).Error("Failed to execute error template")
+		http.Error(w, appErr.Message, appErr.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(appErr.Status)
+	b.WriteTo(w)
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/warrenb95/website/internal/blog"
+	"github.com/warrenb95/website/internal/config"
+	"github.com/warrenb95/website/internal/storage/memory"
+	"github.com/warrenb95/website/views"
+)
+
+func TestRenderError(t *testing.T) {
+	notFound := newError(http.StatusNotFound, "There's no blog called \"htmx\".", blog.ErrNotFound)
+	notFound.Suggestions = []blog.Blog{{Slug: "htmx_and_go", Title: "htmx and go"}}
+
+	tests := map[string]struct {
+		err        error
+		header     http.Header
+		wantStatus int
+		wantType   string
+		wantInBody []string
+		notInBody  []string
+		wantLevel  logrus.Level
+		wantLogged string
+	}{
+		"unexpected error is a 500 page": {
+			err:        errors.New("s3 is down"),
+			wantStatus: http.StatusInternalServerError,
+			wantType:   "text/html; charset=utf-8",
+			wantInBody: []string{"<strong>500</strong>", "Internal Server Error", "Something went wrong on our end"},
+			notInBody:  []string{"s3 is down"},
+			wantLevel:  logrus.ErrorLevel,
+			wantLogged: "s3 is down",
+		},
+		"client error uses the 4xx page": {
+			err:        newError(http.StatusBadRequest, "That page number isn't valid.", errors.New("invalid page")),
+			wantStatus: http.StatusBadRequest,
+			wantType:   "text/html; charset=utf-8",
+			wantInBody: []string{"<strong>400</strong>", "Bad Request", "That page number isn&#39;t valid."},
+			wantLevel:  logrus.InfoLevel,
+			wantLogged: "invalid page",
+		},
+		"status with its own page": {
+			err:        notFound,
+			wantStatus: http.StatusNotFound,
+			wantType:   "text/html; charset=utf-8",
+			wantInBody: []string{"Did you mean", `href="/blog/htmx_and_go"`},
+			wantLevel:  logrus.InfoLevel,
+			wantLogged: "not found",
+		},
+		"wrapped error keeps its status": {
+			err:        errors.Join(errors.New("context"), newError(http.StatusBadRequest, "Bad input.", nil)),
+			wantStatus: http.StatusBadRequest,
+			wantType:   "text/html; charset=utf-8",
+			wantInBody: []string{"Bad input."},
+			wantLevel:  logrus.InfoLevel,
+		},
+		"json": {
+			err:        notFound,
+			header:     http.Header{"Accept": {"application/json"}},
+			wantStatus: http.StatusNotFound,
+			wantType:   "application/json",
+			wantInBody: []string{`"status":404`, `"error":"There's no blog called \"htmx\"."`, `"url":"/blog/htmx_and_go"`},
+			notInBody:  []string{"<html"},
+			wantLevel:  logrus.InfoLevel,
+		},
+		"htmx": {
+			err:        errors.New("s3 is down"),
+			header:     http.Header{"Hx-Request": {"true"}},
+			wantStatus: http.StatusInternalServerError,
+			wantType:   "text/plain; charset=utf-8",
+			wantInBody: []string{"Something went wrong on our end"},
+			notInBody:  []string{"<html", "s3 is down"},
+			wantLevel:  logrus.ErrorLevel,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			logger, hook := test.NewNullLogger()
+			store := memory.NewStore()
+			s := NewServer(config.Default(), store, store, newTestTemplates(t, views.FS), logger)
+
+			req := httptest.NewRequest(http.MethodGet, "/blog/htmx", nil)
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+			rec := httptest.NewRecorder()
+			s.renderError(rec, req, tc.err)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tc.wantType {
+				t.Errorf("content type = %q, want %q", got, tc.wantType)
+			}
+			body := rec.Body.String()
+			for _, want := range tc.wantInBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q\n%s", want, body)
+				}
+			}
+			for _, unwanted := range tc.notInBody {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body contains %q", unwanted)
+				}
+			}
+			if tc.wantType == "application/json" && !json.Valid(rec.Body.Bytes()) {
+				t.Errorf("body isn't valid JSON: %s", body)
+			}
+
+			entry := hook.LastEntry()
+			if entry == nil {
+				t.Fatal("error wasn't logged")
+			}
+			if entry.Level != tc.wantLevel {
+				t.Errorf("logged at %s, want %s", entry.Level, tc.wantLevel)
+			}
+			if entry.Data["status"] != tc.wantStatus {
+				t.Errorf("logged status %v, want %d", entry.Data["status"], tc.wantStatus)
+			}
+			if tc.wantLogged != "" && !strings.Contains(entry.Data[logrus.ErrorKey].(error).Error(), tc.wantLogged) {
+				t.Errorf("logged error %v doesn't contain %q", entry.Data[logrus.ErrorKey], tc.wantLogged)
+			}
+		})
+	}
+}
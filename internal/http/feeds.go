@@ -0,0 +1,143 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/warrenb95/website/internal/blog"
+	"github.com/warrenb95/website/internal/feed"
+)
+
+// feedSize is how many of the newest blogs are in a feed.
+const feedSize = 20
+
+// RSS serves the newest blogs as RSS 2.0, only those with the {tag} if there is one.
+func (s *Server) RSS(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		return s.writeFeed(w, r, feed.RSSContentType, feed.Feed.WriteRSS)
+	})
+}
+
+// Atom serves the newest blogs as Atom 1.0, only those with the {tag} if there is one.
+func (s *Server) Atom(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		return s.writeFeed(w, r, feed.AtomContentType, feed.Feed.WriteAtom)
+	})
+}
+
+// JSONFeed serves the newest blogs as JSON Feed 1.1, only those with the {tag} if there is one.
+func (s *Server) JSONFeed(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		// Let other sites' scripts read the feed, it's public anyway.
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		return s.writeFeed(w, r, feed.JSONContentType, feed.Feed.WriteJSON)
+	})
+}
+
+func (s *Server) writeFeed(w http.ResponseWriter, r *http.Request, contentType string, write func(feed.Feed, io.Writer) error) error {
+	f, err := s.feed(r)
+	if err != nil {
+		return err
+	}
+
+	// Write to a buffer first so a failure can still be sent as an error.
+	var buf bytes.Buffer
+	if err := write(f, &buf); err != nil {
+		return fmt.Errorf("writing feed: %w", err)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// feed builds the feed of the newest blogs with their full content.
+func (s *Server) feed(r *http.Request) (feed.Feed, error) {
+	logger := s.logger.WithContext(r.Context())
+
+	tag := tagVar(r)
+	blogs, err := s.listBlogs(r.Context(), logger, listQuery{Tag: tag})
+	if err != nil {
+		return feed.Feed{}, newError(http.StatusInternalServerError, "The blogs couldn't be loaded.", fmt.Errorf("listing blogs: %w", err))
+	}
+
+	f := feed.Feed{
+		Title:       siteTitle,
+		Description: siteDescription,
+		Author:      siteAuthor,
+		Link:        s.absURL(r, "/"),
+		FeedURL:     s.absURL(r, r.URL.Path),
+	}
+
+	if tag != "" {
+		if len(blogs) == 0 {
+			return feed.Feed{}, tagNotFound(tag)
+		}
+		f.Link = s.absURL(r, "/tags/"+url.PathEscape(tag))
+		f.Title = fmt.Sprintf("%s: %s", siteTitle, tag)
+		f.Description = fmt.Sprintf("Blogs tagged %q.", tag)
+	}
+
+	base := s.absURL(r, "")
+	for _, b := range blogs {
+		if len(f.Items) == feedSize {
+			break
+		}
+
+		published, err := b.UploadedAt()
+		if err != nil {
+			// Feed readers sort by date, an undated blog would sit at the top forever.
+			logger.WithError(err).WithField("slug", b.Slug).Error("Leaving undated blog out of the feed")
+			continue
+		}
+		updated, err := b.UpdatedAt()
+		if err != nil {
+			updated = published
+		}
+
+		if _, err := s.renderBlog(r.Context(), &b, base); err != nil {
+			// The summary is still worth sending without the content.
+			logger.WithError(err).WithField("slug", b.Slug).Error("Failed to render blog for the feed")
+		}
+
+		link := s.absURL(r, "/blog/"+b.Slug)
+		image := b.ThumbnailPath
+		if strings.HasPrefix(image, "/") {
+			image = base + image
+		}
+		f.Items = append(f.Items, feed.Item{
+			ID:        feedID(b, base, link, published),
+			Title:     b.Title,
+			Link:      link,
+			Summary:   b.Summary,
+			Image:     image,
+			Content:   string(b.Content),
+			Published: published,
+			Updated:   updated,
+			Tags:      b.Tags,
+		})
+		if updated.After(f.Updated) {
+			f.Updated = updated
+		}
+	}
+	if f.Updated.IsZero() {
+		f.Updated = time.Now()
+	}
+
+	return f, nil
+}
+
+// feedID is the blog's ID in feeds, a tag URI (RFC 4151) made from its ID so that feed readers don't
+// show it again as a new entry when it's renamed. Blogs without an ID fall back to their link.
+func feedID(b blog.Blog, base, link string, published time.Time) string {
+	u, err := url.Parse(base)
+	if b.ID == "" || err != nil || u.Hostname() == "" {
+		return link
+	}
+	return fmt.Sprintf("tag:%s,%d:%s", u.Hostname(), published.Year(), b.ID)
+}
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warrenb95/website/internal/blog"
+	"github.com/warrenb95/website/internal/feed"
+)
+
+func TestFeeds(t *testing.T) {
+	blogs := []blog.Blog{
+		{Slug: "htmx_and_go", Title: "htmx & Go", Uploaded: "2023-11-03T20:00:00+00:00", Summary: "Using htmx with Go.", Tags: []string{"go", "htmx"}},
+		{Slug: "deploying_to_aws", Uploaded: "2023-06-18T12:15:00+01:00", Updated: "2023-07-01T09:00:00+01:00", Tags: []string{"aws"}},
+		{Slug: "undated"},
+		{Slug: "draft", Uploaded: "2023-12-01T09:00:00+00:00", Draft: true},
+	}
+	markdown := map[string]string{
+		"htmx_and_go":      "# htmx\n\n![diagram](/static/htmx.png)\n",
+		"deploying_to_aws": "# AWS\n",
+	}
+
+	tests := map[string]struct {
+		handler    func(*Server) http.HandlerFunc
+		path       string
+		tag        string
+		wantStatus int
+		wantType   string
+		wantInBody []string
+		notInBody  []string
+	}{
+		"rss": {
+			handler:    func(s *Server) http.HandlerFunc { return s.RSS },
+			path:       "/feed.xml",
+			wantStatus: http.StatusOK,
+			wantType:   feed.RSSContentType,
+			wantInBody: []string{
+				"<title>htmx &amp; Go</title>",
+				"<link>http://example.com/blog/htmx_and_go</link>",
+				"<pubDate>Fri, 03 Nov 2023 20:00:00 +0000</pubDate>",
+				"<pubDate>Sun, 18 Jun 2023 12:15:00 +0100</pubDate>",
+				`&lt;img src=&#34;http://example.com/static/htmx.png&#34;`,
+				`<atom:link href="http://example.com/feed.xml"`,
+			},
+			notInBody: []string{"undated", "/blog/draft"},
+		},
+		"atom": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Atom },
+			path:       "/atom.xml",
+			wantStatus: http.StatusOK,
+			wantType:   feed.AtomContentType,
+			wantInBody: []string{
+				"<updated>2023-11-03T20:00:00Z</updated>",
+				"<id>http://example.com/blog/deploying_to_aws</id>",
+				"<title>deploying to aws</title>",
+				"<published>2023-06-18T12:15:00+01:00</published>",
+				"<updated>2023-07-01T09:00:00+01:00</updated>",
+				`<content type="html">&lt;h1`,
+			},
+			notInBody: []string{"undated", "/blog/draft"},
+		},
+		"json feed": {
+			handler:    func(s *Server) http.HandlerFunc { return s.JSONFeed },
+			path:       "/feed.json",
+			wantStatus: http.StatusOK,
+			wantType:   feed.JSONContentType,
+			wantInBody: []string{
+				`"version": "https://jsonfeed.org/version/1.1"`,
+				`"url": "http://example.com/blog/htmx_and_go"`,
+				`"date_modified": "2023-07-01T09:00:00+01:00"`,
+				`"content_html": "\u003ch1`,
+			},
+			notInBody: []string{"undated", "/blog/draft"},
+		},
+		"tag": {
+			handler:    func(s *Server) http.HandlerFunc { return s.RSS },
+			path:       "/tags/AWS/feed.xml",
+			tag:        "AWS",
+			wantStatus: http.StatusOK,
+			wantType:   feed.RSSContentType,
+			wantInBody: []string{"<title>warrenb95: AWS</title>", "/blog/deploying_to_aws"},
+			notInBody:  []string{"/blog/htmx_and_go"},
+		},
+		"unknown tag": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Atom },
+			path:       "/tags/rust/atom.xml",
+			tag:        "rust",
+			wantStatus: http.StatusNotFound,
+			wantInBody: []string{"There are no blogs tagged &#34;rust&#34;."},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, store := newTestServer(t, blogs...)
+			for slug, md := range markdown {
+				if err := store.PutMarkdown(context.Background(), slug, []byte(md)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.tag != "" {
+				req = mux.SetURLVars(req, map[string]string{"tag": tc.tag})
+			}
+			rec := httptest.NewRecorder()
+			tc.handler(s)(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if tc.wantType != "" {
+				if got := rec.Header().Get("Content-Type"); got != tc.wantType {
+					t.Errorf("content type = %q, want %q", got, tc.wantType)
+				}
+			}
+			body := rec.Body.String()
+			for _, want := range tc.wantInBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q\n%s", want, body)
+				}
+			}
+			for _, unwanted := range tc.notInBody {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body contains %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestFeedIDSurvivesRename(t *testing.T) {
+	ctx := context.Background()
+	b := blog.Blog{ID: "3f1c9a52", Slug: "htmx_and_go", Uploaded: "2023-11-03T20:00:00+00:00"}
+	s, store := newTestServer(t, b)
+
+	atom := func() string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.Atom(rec, httptest.NewRequest(http.MethodGet, "/atom.xml", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		return rec.Body.String()
+	}
+
+	const want = "<id>tag:example.com,2023:3f1c9a52</id>"
+	if body := atom(); !strings.Contains(body, want) {
+		t.Fatalf("body doesn't contain %q\n%s", want, body)
+	}
+
+	if err := store.Delete(ctx, b.Slug); err != nil {
+		t.Fatal(err)
+	}
+	b.Slug, b.Aliases = "htmx_with_go", []string{b.Slug}
+	if err := store.Put(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	body := atom()
+	if !strings.Contains(body, "/blog/htmx_with_go") {
+		t.Fatalf("the renamed blog isn't in the feed\n%s", body)
+	}
+	if !strings.Contains(body, want) {
+		t.Errorf("the ID changed with the slug, body doesn't contain %q\n%s", want, body)
+	}
+}
@@ -1,96 +1,271 @@
 package http
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"html/template"
-	"io"
 	"net/http"
+	"net/url"
 	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/gomarkdown/markdown"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
-	nhtml "golang.org/x/net/html"
-)
 
-// Blog struct
-type Blog struct {
-	ID            string
-	Title         string
-	ThumbnailPath string `dynamodbav:"thumbnail_path"`
-	Uploaded      string
-	Summary       string
-	Content       template.HTML
-}
+	"github.com/warrenb95/website/internal/blog"
+	"github.com/warrenb95/website/internal/cache"
+	"github.com/warrenb95/website/internal/config"
+	"github.com/warrenb95/website/internal/highlight"
+	"github.com/warrenb95/website/internal/sanitize"
+	"github.com/warrenb95/website/internal/templates"
+	"github.com/warrenb95/website/internal/transform"
+)
 
 type Server struct {
-	config         aws.Config
-	dynamoDBClient *dynamodb.Client
-	s3Client       *s3.Client
+	config  config.Config
+	blogs   blog.BlogStore
+	content blog.ContentStore
+
+	templates   *templates.Registry
+	highlighter *highlight.Highlighter
+	transformer *transform.Pipeline
+	sanitizer   *sanitize.Sanitizer
+
+	index       atomic.Pointer[contentIndex]
+	indexBuilds cache.Group[*contentIndex]
+	// rendered caches the rendered blogs, see renderBlog.
+	rendered *cache.Cache[renderedBlog]
 
 	logger *logrus.Logger
 }
 
-func NewServer(region string, logger *logrus.Logger) *Server {
-	// Load the Shared AWS Configuration (~/.aws/config)
-	cfg, err := config.LoadDefaultConfig(context.TODO())
-	if err != nil {
-		logger.Fatal(err)
-	}
-	cfg.Region = region
-
-	// Using the Config value, create the DynamoDB client
-	dynamoDBClient := dynamodb.NewFromConfig(cfg)
+// Pages are the templates the handlers render.
+var Pages = []string{"index.html", "cards", "tag.html", "tags.html", "series.html", "archive.html", "archive-widget", "search.html", "search-suggestions", "about.html", "show.html", "404.html", "4xx.html", "5xx.html"}
 
-	// Create an Amazon S3 service client
-	s3Client := s3.NewFromConfig(cfg)
+func NewServer(cfg config.Config, blogs blog.BlogStore, content blog.ContentStore, tmpl *templates.Registry, logger *logrus.Logger) *Server {
+	policy := sanitize.Policy{
+		Elements:   cfg.Sanitize.Elements,
+		Attributes: cfg.Sanitize.Attributes,
+		Schemes:    cfg.Sanitize.Schemes,
+	}
+	if cfg.Sanitize.Trusted {
+		policy.IframeHosts = cfg.Sanitize.IframeHosts
+	}
+	cacheOpts := cache.Options{
+		MaxEntries:  cfg.Cache.MaxEntries,
+		MaxBytes:    cfg.Cache.MaxBytes,
+		TTL:         cfg.Cache.TTL,
+		LoadTimeout: loadTimeout,
+	}
 
 	return &Server{
-		config:         cfg,
-		dynamoDBClient: dynamoDBClient,
-		s3Client:       s3Client,
-		logger:         logger,
+		config:      cfg,
+		blogs:       blogs,
+		content:     content,
+		templates:   tmpl,
+		highlighter: highlight.New(cfg.Highlight.Theme, cfg.Highlight.LineNumbers),
+		transformer: transform.New(cfg.Markdown.Transformers...),
+		sanitizer:   sanitize.New(policy),
+		indexBuilds: cache.Group[*contentIndex]{Timeout: indexTimeout},
+		rendered:    cache.New(cacheOpts, renderedBlog.size),
+		logger:      logger,
 	}
 }
 
+// loadTimeout bounds loading a blog's markdown to render it. Loads aren't cancelled with the request
+// that started them, since other requests may be waiting on them.
+const loadTimeout = 30 * time.Second
+
+// pageSize is how many blogs are on each page of the index, a multiple of the 3 card columns.
+const pageSize = 9
+
+const (
+	siteTitle       = "warrenb95"
+	siteDescription = "Building this website with Go and htmx, and deploying it to AWS."
+	siteAuthor      = "warrenb95"
+)
+
+// meta is the page metadata rendered by the head template, every page's data embeds it.
+// It's shown by search engines and when the page is shared.
+type meta struct {
+	// Canonical is the absolute URL search engines should index the page under.
+	Canonical string
+	// PageTitle goes before the site's title in the <title>, it's just the site's title when empty.
+	PageTitle   string
+	Description string
+	// Image is the absolute URL of the image shown when the page is shared.
+	Image string
+	// Article is set for blogs, along with when they were published and modified in RFC 3339.
+	Article   bool
+	Published string
+	Modified  string
+	// JSONLD is the page's schema.org structured data.
+	JSONLD any
+}
+
+// indexPage is a page of blog cards on the index, or a tag's page when Tag is set.
+type indexPage struct {
+	meta
+
+	Tag   string
+	Blogs []blog.Blog
+	Page  int
+	// NextPage is 0 on the last page.
+	NextPage int
+}
+
 func (s *Server) Index(w http.ResponseWriter, r *http.Request) {
-	logger := s.logger.WithContext(r.Context())
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		return s.blogPage(w, r, "index.html")
+	})
+}
+
+// Tag renders the first page of blogs with the {tag}.
+func (s *Server) Tag(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		return s.blogPage(w, r, "tag.html")
+	})
+}
 
-	var retBlogs []Blog
-	out, err := s.dynamoDBClient.Scan(context.Background(), &dynamodb.ScanInput{
-		TableName: aws.String("blogs"),
+// tagVar returns the {tag} in the path. Routes match the escaped path so a tag can have a "/" in it,
+// which leaves the tag to unescape.
+func tagVar(r *http.Request) string {
+	tag := mux.Vars(r)["tag"]
+	if unescaped, err := url.PathUnescape(tag); err == nil {
+		return unescaped
+	}
+	return tag
+}
+
+// Blogs renders a page of blog cards for htmx to load onto the end of the index, or a tag's page with ?tag=.
+func (s *Server) Blogs(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		return s.blogPage(w, r, "cards")
 	})
+}
+
+func (s *Server) blogPage(w http.ResponseWriter, r *http.Request, name string) error {
+	logger := s.logger.WithContext(r.Context())
+
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		var err error
+		page, err = strconv.Atoi(p)
+		if err != nil || page < 1 {
+			return newError(http.StatusBadRequest, "That page number isn't valid.", fmt.Errorf("invalid page %q", p))
+		}
+	}
+
+	tag := tagVar(r)
+	if tag == "" {
+		tag = r.URL.Query().Get("tag")
+	}
+
+	retBlogs, err := s.listBlogs(r.Context(), logger, listQuery{Tag: tag})
 	if err != nil {
-		logger.WithError(err).Error("Scanning blogs dynamodb table")
-		http.Error(w, "failed to list blogs", http.StatusInternalServerError)
-		return
+		return newError(http.StatusInternalServerError, "The blogs couldn't be loaded.", fmt.Errorf("listing blogs: %w", err))
+	}
+	if tag != "" && len(retBlogs) == 0 {
+		return tagNotFound(tag)
 	}
 
-	err = attributevalue.UnmarshalListOfMaps(out.Items, &retBlogs)
+	data := indexPage{Tag: tag, Page: page}
+	data.Canonical = s.absURL(r, "/")
+	data.Description = siteDescription
+	if tag != "" {
+		data.Canonical = s.absURL(r, "/tags/"+url.PathEscape(tag))
+		data.PageTitle = fmt.Sprintf("Blogs tagged %s", tag)
+		data.Description = fmt.Sprintf("%d blogs tagged %s.", len(retBlogs), tag)
+	}
+	if page > 1 {
+		data.Canonical += fmt.Sprintf("?page=%d", page)
+		if data.PageTitle == "" {
+			data.PageTitle = fmt.Sprintf("Page %d", page)
+		} else {
+			data.PageTitle += fmt.Sprintf(", page %d", page)
+		}
+	}
+	// Compared before multiplying so huge pages can't overflow. The first page is there with no blogs.
+	if pages := (len(retBlogs) + pageSize - 1) / pageSize; page > 1 && page > pages {
+		return newError(http.StatusNotFound, fmt.Sprintf("There's no page %d.", page), fmt.Errorf("page %d of %d", page, pages))
+	}
+	start := (page - 1) * pageSize
+	if start < len(retBlogs) {
+		end := start + pageSize
+		if end < len(retBlogs) {
+			data.NextPage = page + 1
+		} else {
+			end = len(retBlogs)
+		}
+		data.Blogs = retBlogs[start:end]
+	}
+	for i := range data.Blogs {
+		uploaded, err := data.Blogs[i].UploadedAt()
+		if err != nil {
+			logger.WithError(err).Error("Failed to parse time for blog")
+		}
+		data.Blogs[i].Uploaded = uploaded.Format(time.DateTime)
+	}
+
+	if err := s.templates.Execute(w, name, data); err != nil {
+		return fmt.Errorf("executing %s template: %w", name, err)
+	}
+
+	return nil
+}
+
+// listQuery filters the blogs listed, its zero value lists them all.
+type listQuery struct {
+	// Tag lists the blogs with the tag, ignoring case.
+	Tag string
+	// Since and Until list the blogs uploaded from Since and before Until.
+	Since time.Time
+	Until time.Time
+	// Series lists the parts of the series with the slug.
+	Series string
+}
+
+func (q listQuery) matches(b blog.Blog) bool {
+	if q.Tag != "" && !b.HasTag(q.Tag) {
+		return false
+	}
+	if q.Series != "" && !b.InSeries(q.Series) {
+		return false
+	}
+	if !q.Since.IsZero() || !q.Until.IsZero() {
+		uploaded, err := b.UploadedAt()
+		if err != nil || uploaded.Before(q.Since) || (!q.Until.IsZero() && !uploaded.Before(q.Until)) {
+			return false
+		}
+	}
+	return true
+}
+
+// listBlogs returns every visible blog matching q newest first.
+func (s *Server) listBlogs(ctx context.Context, logger *logrus.Entry, q listQuery) ([]blog.Blog, error) {
+	all, err := s.blogs.List(ctx)
 	if err != nil {
-		logger.WithError(err).Error("Failed to UnmarshalListOfMaps return blogs")
-		http.Error(w, "failed to unmarshal return blogs from DB", http.StatusInternalServerError)
-		return
+		return nil, err
+	}
+
+	retBlogs := all[:0]
+	for _, b := range all {
+		if s.visible(b) && q.matches(b) {
+			retBlogs = append(retBlogs, b)
+		}
 	}
 
 	sort.Slice(retBlogs, func(i, j int) bool {
-		timeA, err := time.Parse("2006-01-02T15:04:05-07:00", retBlogs[i].Uploaded)
+		timeA, err := retBlogs[i].UploadedAt()
 		if err != nil {
 			logger.WithError(err).Error("Failed to parse time for blog")
 			return false
 		}
 
-		timeB, err := time.Parse("2006-01-02T15:04:05-07:00", retBlogs[j].Uploaded)
+		timeB, err := retBlogs[j].UploadedAt()
 		if err != nil {
 			logger.WithError(err).Error("Failed to parse time for blog")
 			return false
@@ -100,134 +275,336 @@ func (s *Server) Index(w http.ResponseWriter, r *http.Request) {
 	})
 
 	for i := range retBlogs {
-		UploadedTime, err := time.Parse("2006-01-02T15:04:05-07:00", retBlogs[i].Uploaded)
+		retBlogs[i].DefaultTitle()
+	}
+
+	return retBlogs, nil
+}
+
+// visible reports whether readers can see the blog, drafts are only shown in development.
+func (s *Server) visible(b blog.Blog) bool {
+	return !b.Draft || s.config.IsDevelopment()
+}
+
+// tagsPage is the tag cloud.
+type tagsPage struct {
+	meta
+
+	Tags []tagCloudItem
+}
+
+type tagCloudItem struct {
+	blog.TagCount
+	// Size is the Bootstrap font size class, fs-1 for the most used tags down to fs-6.
+	Size int
+}
+
+// Tags renders every tag sized by how many blogs have it.
+func (s *Server) Tags(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		blogs, err := s.listBlogs(r.Context(), s.logger.WithContext(r.Context()), listQuery{})
 		if err != nil {
-			logger.WithError(err).Error("Failed to parse time for blog")
+			return newError(http.StatusInternalServerError, "The tags couldn't be loaded.", fmt.Errorf("listing blogs: %w", err))
 		}
 
-		retBlogs[i].Uploaded = UploadedTime.Format(time.DateTime)
-	}
+		counts := blog.CountTags(blogs)
+		most := 0
+		for _, c := range counts {
+			if c.Count > most {
+				most = c.Count
+			}
+		}
 
-	tmpl := template.Must(template.ParseGlob("./views/*"))
-	if err := tmpl.ExecuteTemplate(w, "index.html", retBlogs); err != nil {
-		logger.WithError(err).Error("Failed to execute index template")
-		http.Error(w, "failed to execute index template", http.StatusInternalServerError)
-		return
-	}
+		data := tagsPage{meta: meta{
+			Canonical:   s.absURL(r, "/tags"),
+			PageTitle:   "Tags",
+			Description: fmt.Sprintf("Every topic %s writes about.", siteAuthor),
+		}}
+		for _, c := range counts {
+			// The least used tags are fs-5, which is still readable, up to fs-1.
+			size := 5
+			if most > 1 {
+				size -= 4 * (c.Count - 1) / (most - 1)
+			}
+			data.Tags = append(data.Tags, tagCloudItem{TagCount: c, Size: size})
+		}
 
-	w.WriteHeader(http.StatusOK)
+		if err := s.templates.Execute(w, "tags.html", data); err != nil {
+			return fmt.Errorf("executing tags template: %w", err)
+		}
+		return nil
+	})
 }
 
 func (s *Server) About(w http.ResponseWriter, r *http.Request) {
-	logger := s.logger.WithContext(r.Context())
-
-	tmpl := template.Must(template.ParseGlob("./views/*"))
-	if err := tmpl.ExecuteTemplate(w, "about.html", nil); err != nil {
-		logger.WithError(err).Error("Failed to execute about template")
-		http.Error(w, "failed to execute about template", http.StatusInternalServerError)
-		return
-	}
-	w.WriteHeader(http.StatusOK)
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		data := meta{
+			Canonical:   s.absURL(r, "/about"),
+			PageTitle:   "About",
+			Description: "About " + siteAuthor + ", who writes this blog.",
+		}
+		if err := s.templates.Execute(w, "about.html", data); err != nil {
+			return fmt.Errorf("executing about template: %w", err)
+		}
+		return nil
+	})
 }
 
 func (s *Server) Show(w http.ResponseWriter, r *http.Request) {
-	logger := s.logger.WithContext(r.Context())
+	s.handle(w, r, s.show)
+}
+
+// showPage is a blog's page.
+type showPage struct {
+	meta
+	blog.Blog
+
+	// TOC is the blog's table of contents, nil when it's hidden.
+	TOC []*tocEntry
+	// SeriesNav is set when the blog is a part of a series.
+	SeriesNav *seriesNav
+	Related   []blog.Blog
+}
+
+// seriesNav is where a blog is in its series.
+type seriesNav struct {
+	Name string
+	Slug string
+	// Part counts from 1 in reading order.
+	Part  int
+	Total int
+	// Prev and Next are nil at the start and end of the series.
+	Prev *blog.Blog
+	Next *blog.Blog
+}
 
-	title := mux.Vars(r)["title"]
-	if title == "" {
+func (s *Server) show(w http.ResponseWriter, r *http.Request) error {
+	slug := mux.Vars(r)["slug"]
+	if slug == "" {
 		// TODO: redirect back to the index page.
-		logger.Warn("Empty blog title in show request")
-		http.Error(w, "empty blog title", http.StatusBadRequest)
-		return
+		return newError(http.StatusBadRequest, "Which blog are you looking for?", errors.New("empty blog slug"))
+	}
+	if !blog.ValidSlug(slug) {
+		// Don't let odd slugs anywhere near the stores.
+		return s.blogNotFound(w, r, slug)
 	}
-	logger = logger.WithField("title", title)
 
-	// Using the Config value, create the DynamoDB client
-	item, err := s.dynamoDBClient.GetItem(context.Background(), &dynamodb.GetItemInput{
-		TableName: aws.String("blogs"),
-		Key: map[string]types.AttributeValue{
-			"title": &types.AttributeValueMemberS{Value: title},
-		},
-	})
+	// The blog is still worth showing without its series and related blogs.
+	idx, err := s.currentIndex(r.Context())
 	if err != nil {
-		logger.WithError(err).Error("Failed to get blog data")
-		http.Error(w, "failed to get blog data", http.StatusInternalServerError)
-		return
+		s.logger.WithContext(r.Context()).WithError(err).Error("Failed to load the content index")
+		idx = &contentIndex{}
+	}
+	b, ok := idx.blogs[slug]
+	if !ok {
+		// It may have been added since the index was built.
+		b, err = s.blogs.Get(r.Context(), slug)
+		if errors.Is(err, blog.ErrNotFound) || (err == nil && !s.visible(b)) {
+			return s.blogNotFound(w, r, slug)
+		}
+		if err != nil {
+			return fmt.Errorf("getting blog %q: %w", slug, err)
+		}
+		b.DefaultTitle()
 	}
 
-	var blog Blog
-	err = attributevalue.UnmarshalMap(item.Item, &blog)
+	rendered, err := s.renderBlog(r.Context(), &b, "")
 	if err != nil {
-		logger.WithError(err).Error("Failed unmarshal blog")
-		http.Error(w, "failed to unmarshal blog data", http.StatusInternalServerError)
-		return
+		return err
 	}
-	blog.Title = strings.ReplaceAll(blog.Title, "_", " ")
 
-	// Get the first page of results for ListObjectsV2 for a bucket
-	object, err := s.s3Client.GetObject(context.Background(), &s3.GetObjectInput{
-		Bucket: aws.String("warrenb95-blog"),
-		Key:    aws.String(fmt.Sprintf("blogs/%s.md", title)),
-	})
-	if err != nil {
-		logger.WithError(err).Error("Failed to get blog content")
-		http.Error(w, "failed to get blog content", http.StatusInternalServerError)
-		return
+	data := showPage{Blog: b, meta: s.blogMeta(r, b)}
+	toc, count := buildTOC(rendered.Headings, s.config.TOC.Depth)
+	if show := rendered.TOC; (show == nil && count >= s.config.TOC.MinHeadings) || (show != nil && *show) {
+		data.TOC = toc
+	}
+	if b.Series != "" {
+		data.SeriesNav = seriesNavOf(idx, b)
+	}
+	for _, related := range idx.related[b.Slug] {
+		data.Related = append(data.Related, idx.blogs[related])
 	}
 
-	fbytes, err := io.ReadAll(object.Body)
-	if err != nil {
-		logger.WithError(err).Error("Failed to read all blog content")
-		http.Error(w, "failed to read blog content", http.StatusInternalServerError)
-		return
+	if err := s.templates.Execute(w, "show.html", data); err != nil {
+		return fmt.Errorf("executing show template: %w", err)
 	}
 
-	output := markdown.ToHTML(fbytes, nil, nil)
-	htmlContent := template.HTML(string(output))
+	return nil
+}
 
-	doc, err := nhtml.Parse(strings.NewReader(string(htmlContent)))
-	if err != nil {
-		logger.WithError(err).Error("failed to parse html")
-		http.Error(w, "failed to parse html", http.StatusInternalServerError)
-		return
+// seriesNavOf finds the blog's place in its series in the content index.
+func seriesNavOf(idx *contentIndex, b blog.Blog) *seriesNav {
+	slug := blog.SeriesSlug(b.Series)
+	parts := idx.series[slug]
+
+	nav := &seriesNav{Name: b.Series, Slug: slug, Total: len(parts)}
+	for i := range parts {
+		if parts[i].Slug != b.Slug {
+			continue
+		}
+		nav.Part = i + 1
+		if i > 0 {
+			nav.Prev = &parts[i-1]
+		}
+		if i < len(parts)-1 {
+			nav.Next = &parts[i+1]
+		}
+	}
+	if nav.Part == 0 {
+		// A draft in development, or a blog whose series changed since the index was built.
+		return nil
 	}
 
-	var htmlNodeClassAdder func(n *nhtml.Node)
-	htmlNodeClassAdder = func(n *nhtml.Node) {
-		if n.Type == nhtml.ElementNode && n.Data == "img" {
-			n.Attr = append(n.Attr, nhtml.Attribute{
-				Namespace: doc.Namespace,
-				Key:       "class",
-				Val:       "img-fluid",
-			})
+	return nav
+}
+
+// seriesPage lists the parts of a series in reading order.
+type seriesPage struct {
+	meta
+
+	Name  string
+	Parts []blog.Blog
+}
+
+// Series renders the {name} series' parts in reading order.
+func (s *Server) Series(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		slug := mux.Vars(r)["name"]
+		parts, err := s.listBlogs(r.Context(), s.logger.WithContext(r.Context()), listQuery{Series: slug})
+		if err != nil {
+			return newError(http.StatusInternalServerError, "The series couldn't be loaded.", fmt.Errorf("listing series %q: %w", slug, err))
 		}
-		if n.Data == "a" {
-			n.Attr = append(n.Attr, nhtml.Attribute{
-				Namespace: doc.Namespace,
-				Key:       "target",
-				Val:       "_blank",
-			})
+		if len(parts) == 0 {
+			return newError(http.StatusNotFound, "There's no series by that name.", fmt.Errorf("series %q: %w", slug, blog.ErrNotFound))
 		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			htmlNodeClassAdder(c)
+		blog.SortParts(parts)
+
+		data := seriesPage{Name: parts[0].Series, Parts: parts}
+		data.Canonical = s.absURL(r, "/series/"+slug)
+		data.PageTitle = data.Name
+		data.Description = fmt.Sprintf("A series of %d blogs.", len(parts))
+
+		if err := s.templates.Execute(w, "series.html", data); err != nil {
+			return fmt.Errorf("executing series template: %w", err)
 		}
+		return nil
+	})
+}
+
+// blogPosting is the schema.org BlogPosting structured data for a blog, see https://schema.org/BlogPosting.
+type blogPosting struct {
+	Context          string   `json:"@context"`
+	Type             string   `json:"@type"`
+	Headline         string   `json:"headline"`
+	Description      string   `json:"description,omitempty"`
+	Image            string   `json:"image,omitempty"`
+	URL              string   `json:"url"`
+	MainEntityOfPage string   `json:"mainEntityOfPage"`
+	DatePublished    string   `json:"datePublished,omitempty"`
+	DateModified     string   `json:"dateModified,omitempty"`
+	Keywords         string   `json:"keywords,omitempty"`
+	Author           ldPerson `json:"author"`
+}
+
+type ldPerson struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// blogMeta is the page metadata for a blog.
+func (s *Server) blogMeta(r *http.Request, b blog.Blog) meta {
+	m := meta{
+		Canonical:   b.CanonicalURL,
+		PageTitle:   b.Title,
+		Description: b.Summary,
+		Image:       ogImageURL(b),
+		Article:     true,
+	}
+	if m.Canonical == "" {
+		m.Canonical = s.absURL(r, "/blog/"+b.Slug)
+	}
+	if strings.HasPrefix(m.Image, "/") {
+		m.Image = s.absURL(r, m.Image)
+	}
+	if t, err := b.UploadedAt(); err == nil {
+		m.Published = t.Format(time.RFC3339)
+	}
+	if t, err := b.UpdatedAt(); err == nil {
+		m.Modified = t.Format(time.RFC3339)
 	}
-	htmlNodeClassAdder(doc)
 
-	var b bytes.Buffer
-	err = nhtml.Render(&b, doc)
+	m.JSONLD = blogPosting{
+		Context:          "https://schema.org",
+		Type:             "BlogPosting",
+		Headline:         b.Title,
+		Description:      b.Summary,
+		Image:            m.Image,
+		URL:              s.absURL(r, "/blog/"+b.Slug),
+		MainEntityOfPage: m.Canonical,
+		DatePublished:    m.Published,
+		DateModified:     m.Modified,
+		Keywords:         strings.Join(b.Tags, ", "),
+		Author: ldPerson{
+			Type: "Person",
+			Name: siteAuthor,
+			URL:  s.absURL(r, "/about"),
+		},
+	}
+
+	return m
+}
+
+// tagNotFound is the error for a tag no blogs have.
+func tagNotFound(tag string) error {
+	return newError(http.StatusNotFound, fmt.Sprintf("There are no blogs tagged %q.", tag), fmt.Errorf("tag %q: %w", tag, blog.ErrNotFound))
+}
+
+// maxSuggestions is how many similarly titled blogs are suggested on the 404 page.
+const maxSuggestions = 3
+
+// NotFound renders the 404 page for unknown routes.
+func (s *Server) NotFound(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(http.ResponseWriter, *http.Request) error {
+		return newError(http.StatusNotFound, "This page doesn't exist.", fmt.Errorf("no route for %s", r.URL.Path))
+	})
+}
+
+// blogNotFound redirects renamed blogs to their new slug, otherwise it's a 404 suggesting blogs with similar slugs.
+func (s *Server) blogNotFound(w http.ResponseWriter, r *http.Request, slug string) error {
+	logger := s.logger.WithContext(r.Context())
+	blogs, err := s.listBlogs(r.Context(), logger, listQuery{})
 	if err != nil {
-		logger.WithError(err).Error("Failed to render html to bytes")
-		http.Error(w, "failed to render html", http.StatusInternalServerError)
-		return
+		// The redirects and suggestions are a nice to have, still send the 404.
+		logger.WithError(err).Error("Failed to list blogs for redirects and suggestions")
 	}
 
-	blog.Content = template.HTML(b.String())
+	if to, ok := blog.Redirects(blogs)[slug]; ok {
+		http.Redirect(w, r, "/blog/"+to, http.StatusMovedPermanently)
+		return nil
+	}
 
-	tmpl := template.Must(template.ParseGlob("./views/*"))
-	if err := tmpl.ExecuteTemplate(w, "show.html", blog); err != nil {
-		logger.WithError(err).Error("Failed to execute show template")
-		http.Error(w, "failed to exeute show templated", http.StatusInternalServerError)
-		return
+	appErr := newError(
+		http.StatusNotFound,
+		fmt.Sprintf("There's no blog called %q.", strings.ReplaceAll(slug, "_", " ")),
+		fmt.Errorf("blog %q: %w", slug, blog.ErrNotFound),
+	)
+	appErr.Suggestions = blog.Similar(blogs, slug, maxSuggestions)
+
+	return appErr
+}
+
+// absURL makes path absolute using the configured site URL, or the request's host if there isn't one.
+// The config needs a site URL in production, so the host is only trusted in development.
+func (s *Server) absURL(r *http.Request, path string) string {
+	if s.config.SiteURL != "" {
+		return strings.TrimRight(s.config.SiteURL, "/") + path
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
 	}
+
+	return scheme + "://" + r.Host + path
 }
@@ -0,0 +1,904 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/warrenb95/website/internal/blog"
+	"github.com/warrenb95/website/internal/config"
+	"github.com/warrenb95/website/internal/storage/memory"
+	"github.com/warrenb95/website/internal/templates"
+	"github.com/warrenb95/website/views"
+)
+
+func newTestServer(t *testing.T, blogs ...blog.Blog) (*Server, *memory.Store) {
+	t.Helper()
+
+	store := memory.NewStore()
+	for _, b := range blogs {
+		if err := store.Put(context.Background(), b); err != nil {
+			t.Fatalf("putting blog %q: %v", b.Slug, err)
+		}
+	}
+
+	logger, _ := test.NewNullLogger()
+	s := NewServer(config.Default(), store, store, newTestTemplates(t, views.FS), logger)
+
+	return s, store
+}
+
+func newTestTemplates(t *testing.T, fsys fs.FS) *templates.Registry {
+	t.Helper()
+
+	tmpl, err := templates.New(fsys, Pages...)
+	if err != nil {
+		t.Fatalf("parsing templates: %v", err)
+	}
+
+	return tmpl
+}
+
+func TestIndex(t *testing.T) {
+	blogs := []blog.Blog{
+		{Slug: "oldest", Uploaded: "2023-01-05T09:00:00+00:00", Summary: "the first one"},
+		{Slug: "newest", Uploaded: "2023-11-03T20:30:00+00:00", Summary: "the latest one"},
+		{Slug: "middle", Uploaded: "2023-06-18T12:15:00+01:00", Summary: "somewhere in between"},
+		{Slug: "draft", Uploaded: "2023-12-01T09:00:00+00:00", Summary: "not finished yet", Draft: true},
+	}
+
+	tests := map[string]struct {
+		views      string
+		storeErr   error
+		wantStatus int
+		wantInBody []string
+		notInBody  []string
+		wantOrder  []string
+	}{
+		"lists blogs newest first": {
+			wantStatus: http.StatusOK,
+			wantInBody: []string{"the first one", "the latest one", "somewhere in between"},
+			wantOrder:  []string{"/blog/newest", "/blog/middle", "/blog/oldest"},
+			notInBody:  []string{"/blog/draft"},
+		},
+		"formats uploaded dates": {
+			wantStatus: http.StatusOK,
+			wantInBody: []string{"2023-11-03 20:30:00", "2023-06-18 12:15:00", "2023-01-05 09:00:00"},
+		},
+		"store failure": {
+			storeErr:   errors.New("dynamodb is down"),
+			wantStatus: http.StatusInternalServerError,
+			wantInBody: []string{"500", "The blogs couldn&#39;t be loaded."},
+		},
+		"template execution failure": {
+			views:      "testdata/broken",
+			wantStatus: http.StatusInternalServerError,
+			wantInBody: []string{"error page: Something went wrong on our end"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, store := newTestServer(t, blogs...)
+			store.Err = tc.storeErr
+			if tc.views != "" {
+				s.templates = newTestTemplates(t, os.DirFS(tc.views))
+			}
+
+			rec := httptest.NewRecorder()
+			s.Index(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			body := rec.Body.String()
+			for _, want := range tc.wantInBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q", want)
+				}
+			}
+			for _, unwanted := range tc.notInBody {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body contains %q", unwanted)
+				}
+			}
+			assertOrder(t, body, tc.wantOrder)
+			if strings.Contains(body, "before the failure") {
+				t.Error("body contains a partially rendered template")
+			}
+		})
+	}
+}
+
+func TestIndexPagination(t *testing.T) {
+	var blogs []blog.Blog
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2*pageSize+1; i++ {
+		blogs = append(blogs, blog.Blog{
+			Slug:     fmt.Sprintf("blog_%02d", i),
+			Uploaded: start.AddDate(0, 0, i).Format(blog.UploadedLayout),
+		})
+	}
+
+	tests := map[string]struct {
+		handler    func(*Server) http.HandlerFunc
+		query      string
+		wantStatus int
+		wantFirst  string
+		wantLast   string
+		notInBody  []string
+		wantInBody []string
+	}{
+		"first page": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Index },
+			wantStatus: http.StatusOK,
+			wantFirst:  "/blog/blog_18",
+			wantLast:   "/blog/blog_10",
+			notInBody:  []string{"/blog/blog_09"},
+			wantInBody: []string{`hx-get="/blogs?page=2"`, "<!doctype html>"},
+		},
+		"fragment": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Blogs },
+			query:      "?page=2",
+			wantStatus: http.StatusOK,
+			wantFirst:  "/blog/blog_09",
+			wantLast:   "/blog/blog_01",
+			notInBody:  []string{"/blog/blog_10", "/blog/blog_00", "<!doctype html>"},
+			wantInBody: []string{`hx-get="/blogs?page=3"`},
+		},
+		"last page": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Blogs },
+			query:      "?page=3",
+			wantStatus: http.StatusOK,
+			wantFirst:  "/blog/blog_00",
+			wantLast:   "/blog/blog_00",
+			notInBody:  []string{"load-more"},
+		},
+		"past the last page": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Blogs },
+			query:      "?page=4",
+			wantStatus: http.StatusNotFound,
+			notInBody:  []string{"/blog/", "load-more"},
+		},
+		"huge page": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Index },
+			query:      "?page=2049638230412172402",
+			wantStatus: http.StatusNotFound,
+			wantInBody: []string{"There&#39;s no page 2049638230412172402."},
+		},
+		"invalid page": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Index },
+			query:      "?page=0",
+			wantStatus: http.StatusBadRequest,
+			wantInBody: []string{"That page number isn&#39;t valid."},
+		},
+		"non numeric page": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Blogs },
+			query:      "?page=two",
+			wantStatus: http.StatusBadRequest,
+			wantInBody: []string{"That page number isn&#39;t valid."},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, _ := newTestServer(t, blogs...)
+
+			rec := httptest.NewRecorder()
+			tc.handler(s)(rec, httptest.NewRequest(http.MethodGet, "/"+tc.query, nil))
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			body := rec.Body.String()
+			if tc.wantFirst != "" {
+				assertOrder(t, body, []string{tc.wantFirst, tc.wantLast})
+			}
+			for _, want := range tc.wantInBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q", want)
+				}
+			}
+			for _, unwanted := range tc.notInBody {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body contains %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestAbout(t *testing.T) {
+	tests := map[string]struct {
+		views      string
+		wantStatus int
+		wantInBody string
+	}{
+		"renders about page": {
+			wantStatus: http.StatusOK,
+			wantInBody: "about me",
+		},
+		"template failure": {
+			views:      "testdata/broken",
+			wantStatus: http.StatusInternalServerError,
+			wantInBody: "error page: Something went wrong on our end",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, _ := newTestServer(t)
+			if tc.views != "" {
+				s.templates = newTestTemplates(t, os.DirFS(tc.views))
+			}
+
+			rec := httptest.NewRecorder()
+			s.About(rec, httptest.NewRequest(http.MethodGet, "/about", nil))
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tc.wantInBody) {
+				t.Errorf("body doesn't contain %q", tc.wantInBody)
+			}
+		})
+	}
+}
+
+func TestShow(t *testing.T) {
+	markdown := "# Heading\n\nSome text with [a link](https://example.com) and [one to me](/about).\n\n![an image](/static/image.png)\n"
+
+	tests := map[string]struct {
+		slug       string
+		siteURL    string
+		wantHeader map[string]string
+		markdown   string
+		views      string
+		storeErr   error
+		wantStatus int
+		wantInBody []string
+		notInBody  []string
+	}{
+		"renders markdown": {
+			slug:       "my_first_blog",
+			markdown:   markdown,
+			wantStatus: http.StatusOK,
+			wantInBody: []string{
+				"<strong>my first blog</strong>",
+				`<h1 id="heading">Heading<a class="heading-anchor" href="#heading" aria-label="Link to this section">#</a></h1>`,
+				`<a href="https://example.com" target="_blank" rel="noopener noreferrer">a link</a>`,
+				`<a href="/about">one to me</a>`,
+				`<img src="/static/image.png" alt="an image" class="img-fluid" loading="lazy" decoding="async"/>`,
+			},
+		},
+		"sanitizes html": {
+			slug:       "my_first_blog",
+			markdown:   "<script>alert(1)</script>\n\n[click](javascript:alert(2))\n\n<p onclick=\"alert(3)\">hi</p>\n",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{"<p><a>click</a></p>", "<p>hi</p>"},
+			notInBody:  []string{"alert("},
+		},
+		"highlights code": {
+			slug:       "my_first_blog",
+			markdown:   "```go {2} title=\"main.go\"\npackage main\nfunc main() {}\n```\n",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{
+				`<figure class="code-block"><figcaption class="code-title">main.go</figcaption><pre class="chroma">`,
+				`<span class="kn">package</span>`,
+				`<span class="line hl"><span class="cl"><span class="kd">func</span>`,
+				`<link rel="stylesheet" href="/highlight.css" />`,
+			},
+			notInBody: []string{"hljs", `class="language-go"`},
+		},
+		"highlights code without a language": {
+			slug:       "my_first_blog",
+			markdown:   "```\nplain <text>\n```\n",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{`<pre class="chroma"><code><span class="line"><span class="cl">plain &lt;text&gt;`},
+		},
+		"table of contents": {
+			slug:       "my_first_blog",
+			markdown:   "## One\n\n### One point one\n\n## Two\n",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{`<nav class="toc text-light mb-4" aria-label="Contents">`, `<a href="#one_point_one" class="link-light">One point one</a>`},
+		},
+		"too few headings for a table of contents": {
+			slug:       "my_first_blog",
+			markdown:   "## One\n\n## Two\n",
+			wantStatus: http.StatusOK,
+			notInBody:  []string{`aria-label="Contents"`},
+		},
+		"table of contents from front matter": {
+			slug:       "my_first_blog",
+			markdown:   "---\ntoc: true\n---\n## One\n",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{`aria-label="Contents"`, `<a href="#one" class="link-light">One</a>`},
+		},
+		"no table of contents from front matter": {
+			slug:       "my_first_blog",
+			markdown:   "---\ntoc: false\n---\n## One\n\n### One point one\n\n## Two\n",
+			wantStatus: http.StatusOK,
+			notInBody:  []string{`aria-label="Contents"`},
+		},
+		"strips front matter": {
+			slug:       "my_first_blog",
+			markdown:   "---\ntitle: My First Blog!\nsummary: The first one.\n---\n# Heading\n",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{"<strong>My First Blog!</strong>", `<h1 id="heading">Heading`},
+			notInBody:  []string{"summary: The first one."},
+		},
+		"canonical from the request host": {
+			slug:       "my_first_blog",
+			markdown:   markdown,
+			wantStatus: http.StatusOK,
+			wantInBody: []string{`<link rel="canonical" href="http://example.com/blog/my_first_blog" />`},
+		},
+		"canonical from the site url": {
+			slug:       "my_first_blog",
+			markdown:   markdown,
+			siteURL:    "https://warrenb95.dev",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{`<link rel="canonical" href="https://warrenb95.dev/blog/my_first_blog" />`},
+		},
+		"canonical from front matter": {
+			slug:       "my_first_blog",
+			markdown:   "---\ncanonical_url: https://dev.to/warrenb95/my-first-blog\n---\n# Heading\n",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{`<link rel="canonical" href="https://dev.to/warrenb95/my-first-blog" />`},
+		},
+		"alias redirects to the slug": {
+			slug:       "first_blog",
+			wantStatus: http.StatusMovedPermanently,
+			wantHeader: map[string]string{"Location": "/blog/my_first_blog"},
+		},
+		"invalid slug": {
+			slug:       "..",
+			wantStatus: http.StatusNotFound,
+		},
+		"draft": {
+			slug:       "draft_blog",
+			wantStatus: http.StatusNotFound,
+		},
+		"empty slug": {
+			slug:       "",
+			wantStatus: http.StatusBadRequest,
+			wantInBody: []string{"Which blog are you looking for?"},
+		},
+		"missing blog": {
+			slug:       "does_not_exist",
+			wantStatus: http.StatusNotFound,
+			wantInBody: []string{`There&#39;s no blog called &#34;does not exist&#34;.`, `href="/"`},
+			notInBody:  []string{"Did you mean"},
+		},
+		"missing blog suggestions": {
+			slug:       "my_frist_blog",
+			wantStatus: http.StatusNotFound,
+			wantInBody: []string{"Did you mean", `href="/blog/my_first_blog"`},
+		},
+		"missing content": {
+			slug:       "my_first_blog",
+			wantStatus: http.StatusInternalServerError,
+			wantInBody: []string{"Internal Server Error"},
+		},
+		"store failure": {
+			slug:       "my_first_blog",
+			storeErr:   errors.New("dynamodb is down"),
+			wantStatus: http.StatusInternalServerError,
+			wantInBody: []string{"Internal Server Error"},
+			notInBody:  []string{"dynamodb is down"},
+		},
+		"template failure": {
+			slug:       "my_first_blog",
+			markdown:   markdown,
+			views:      "testdata/broken",
+			wantStatus: http.StatusInternalServerError,
+			wantInBody: []string{"error page: Something went wrong on our end"},
+			notInBody:  []string{"before the failure"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, store := newTestServer(t,
+				blog.Blog{Slug: "my_first_blog", Aliases: []string{"first_blog"}},
+				blog.Blog{Slug: "draft_blog", Draft: true},
+			)
+			s.config.SiteURL = tc.siteURL
+			if tc.markdown != "" {
+				if err := store.PutMarkdown(context.Background(), "my_first_blog", []byte(tc.markdown)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			store.Err = tc.storeErr
+			if tc.views != "" {
+				s.templates = newTestTemplates(t, os.DirFS(tc.views))
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/blog/"+tc.slug, nil)
+			req = mux.SetURLVars(req, map[string]string{"slug": tc.slug})
+			rec := httptest.NewRecorder()
+			s.Show(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			for k, want := range tc.wantHeader {
+				if got := rec.Header().Get(k); got != want {
+					t.Errorf("%s = %q, want %q", k, got, want)
+				}
+			}
+			body := rec.Body.String()
+			for _, want := range tc.wantInBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q\n%s", want, body)
+				}
+			}
+			for _, unwanted := range tc.notInBody {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body contains %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+// assertOrder checks each of want appears in body after the one before it.
+func assertOrder(t *testing.T, body string, want []string) {
+	t.Helper()
+
+	last := -1
+	for _, w := range want {
+		i := strings.Index(body, w)
+		if i < 0 {
+			t.Errorf("body doesn't contain %q", w)
+			return
+		}
+		if i < last {
+			t.Errorf("%q is out of order", w)
+		}
+		last = i
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	s, _ := newTestServer(t, blog.Blog{Slug: "my_first_blog"})
+
+	rec := httptest.NewRecorder()
+	s.NotFound(rec, httptest.NewRequest(http.MethodGet, "/does/not/exist", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "This page doesn&#39;t exist.") {
+		t.Errorf("body doesn't explain the page doesn't exist\n%s", body)
+	}
+	if strings.Contains(body, "Did you mean") {
+		t.Error("body contains suggestions for an unknown route")
+	}
+}
+
+func TestPageMetadata(t *testing.T) {
+	s, store := newTestServer(t, blog.Blog{
+		Slug:          "htmx_and_go",
+		Title:         "htmx & Go",
+		Summary:       "Using htmx with Go.",
+		ThumbnailPath: "/static/htmx.png",
+		Uploaded:      "2023-11-03T20:00:00+00:00",
+		Updated:       "2023-11-05T09:00:00+00:00",
+		Tags:          []string{"go", "htmx"},
+	}, blog.Blog{Slug: "no_thumbnail", Uploaded: "2023-01-05T09:00:00+00:00"})
+	for _, slug := range []string{"htmx_and_go", "no_thumbnail"} {
+		if err := store.PutMarkdown(context.Background(), slug, []byte("# htmx\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]struct {
+		handler    http.HandlerFunc
+		path       string
+		vars       map[string]string
+		wantInBody []string
+		notInBody  []string
+	}{
+		"blog": {
+			handler: s.Show,
+			path:    "/blog/htmx_and_go",
+			vars:    map[string]string{"slug": "htmx_and_go"},
+			wantInBody: []string{
+				"<title>htmx &amp; Go | 😄 warrenb95</title>",
+				`<meta name="description" content="Using htmx with Go." />`,
+				`<meta property="og:title" content="htmx &amp; Go" />`,
+				`<meta property="og:type" content="article" />`,
+				`<meta property="og:url" content="http://example.com/blog/htmx_and_go" />`,
+				`<meta property="og:image" content="http://example.com/static/htmx.png" />`,
+				`<meta property="article:published_time" content="2023-11-03T20:00:00Z" />`,
+				`<meta property="article:modified_time" content="2023-11-05T09:00:00Z" />`,
+				`<meta name="twitter:card" content="summary_large_image" />`,
+				`<meta name="twitter:image" content="http://example.com/static/htmx.png" />`,
+				`<a href="/tags/go" class="badge rounded-pill text-bg-primary text-decoration-none">#go</a>`,
+				`<script type="application/ld+json">{"@context":"https://schema.org","@type":"BlogPosting","headline":"htmx \u0026 Go",`,
+				`"datePublished":"2023-11-03T20:00:00Z","dateModified":"2023-11-05T09:00:00Z","keywords":"go, htmx","author":{"@type":"Person","name":"warrenb95","url":"http://example.com/about"}}</script>`,
+			},
+		},
+		"index": {
+			handler: s.Index,
+			path:    "/",
+			wantInBody: []string{
+				`src="/static/htmx.png"`,
+				"<title>😄 warrenb95</title>",
+				`<meta name="description" content="Building this website`,
+				`<meta property="og:type" content="website" />`,
+				`<meta name="twitter:card" content="summary" />`,
+			},
+			notInBody: []string{"application/ld+json", "og:image"},
+		},
+		"generated image": {
+			handler: s.Show,
+			path:    "/blog/no_thumbnail",
+			vars:    map[string]string{"slug": "no_thumbnail"},
+			wantInBody: []string{
+				`<meta property="og:image" content="http://example.com/og/no_thumbnail.png" />`,
+				`<meta name="twitter:card" content="summary_large_image" />`,
+			},
+		},
+		"generated card image": {
+			handler:    s.Index,
+			path:       "/",
+			wantInBody: []string{`src="/og/no_thumbnail.png"`},
+		},
+		"about": {
+			handler:    s.About,
+			path:       "/about",
+			wantInBody: []string{"<title>About | 😄 warrenb95</title>"},
+		},
+		"error": {
+			handler:    s.NotFound,
+			path:       "/does/not/exist",
+			wantInBody: []string{"<title>Not Found | 😄 warrenb95</title>"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.vars != nil {
+				req = mux.SetURLVars(req, tc.vars)
+			}
+			rec := httptest.NewRecorder()
+			tc.handler(rec, req)
+
+			body := rec.Body.String()
+			for _, want := range tc.wantInBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q\n%s", want, body)
+				}
+			}
+			for _, unwanted := range tc.notInBody {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body contains %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestTags(t *testing.T) {
+	s, _ := newTestServer(t,
+		// The tag's named "go" after its most common spelling.
+		blog.Blog{Slug: "htmx_and_go", Tags: []string{"go", "htmx"}, Uploaded: "2023-03-01T00:00:00+00:00"},
+		blog.Blog{Slug: "deploying_to_aws", Tags: []string{"Go", "aws"}, Uploaded: "2023-02-01T00:00:00+00:00"},
+		blog.Blog{Slug: "more_go", Tags: []string{"go"}, Uploaded: "2023-01-01T00:00:00+00:00"},
+		blog.Blog{Slug: "draft", Tags: []string{"secret"}, Draft: true},
+	)
+
+	rec := httptest.NewRecorder()
+	s.Tags(rec, httptest.NewRequest(http.MethodGet, "/tags", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	assertOrder(t, body, []string{
+		`<a href="/tags/aws" class="link-light link-underline-opacity-0 fs-5"`,
+		`aws <small class="text-muted fs-6">(1)</small>`,
+		`<a href="/tags/go" class="link-light link-underline-opacity-0 fs-1"`,
+		`go <small class="text-muted fs-6">(3)</small>`,
+		`<a href="/tags/htmx" class="link-light link-underline-opacity-0 fs-5"`,
+	})
+	if strings.Contains(body, "secret") {
+		t.Error("draft's tag is in the cloud")
+	}
+}
+
+func TestTag(t *testing.T) {
+	var blogs []blog.Blog
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < pageSize+1; i++ {
+		blogs = append(blogs, blog.Blog{
+			Slug:     fmt.Sprintf("go_%02d", i),
+			Uploaded: start.AddDate(0, 0, i).Format(blog.UploadedLayout),
+			Tags:     []string{"Go"},
+		})
+	}
+	blogs = append(blogs, blog.Blog{Slug: "aws", Uploaded: start.Format(blog.UploadedLayout), Tags: []string{"aws"}})
+
+	tests := map[string]struct {
+		handler    func(*Server) http.HandlerFunc
+		target     string
+		tag        string
+		wantStatus int
+		wantInBody []string
+		notInBody  []string
+	}{
+		"first page": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Tag },
+			target:     "/tags/go",
+			tag:        "go",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{
+				"<title>Blogs tagged go | 😄 warrenb95</title>",
+				`<link rel="canonical" href="http://example.com/tags/go" />`,
+				"<strong>#go</strong>",
+				`href="/blog/go_09"`,
+				`href="/tags/go?page=2"`,
+				`hx-get="/blogs?page=2&tag=go"`,
+				`href="/tags/go/feed.xml"`,
+			},
+			notInBody: []string{`href="/blog/go_00"`, `href="/blog/aws"`},
+		},
+		"more cards": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Blogs },
+			target:     "/blogs?page=2&tag=go",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{`href="/blog/go_00"`},
+			notInBody:  []string{`href="/blog/go_01"`, `href="/blog/aws"`, "Load more"},
+		},
+		"unknown tag": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Tag },
+			target:     "/tags/rust",
+			tag:        "rust",
+			wantStatus: http.StatusNotFound,
+			wantInBody: []string{"There are no blogs tagged &#34;rust&#34;."},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, _ := newTestServer(t, blogs...)
+
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.tag != "" {
+				req = mux.SetURLVars(req, map[string]string{"tag": tc.tag})
+			}
+			rec := httptest.NewRecorder()
+			tc.handler(s)(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			body := rec.Body.String()
+			for _, want := range tc.wantInBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q\n%s", want, body)
+				}
+			}
+			for _, unwanted := range tc.notInBody {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body contains %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestTagEscaping(t *testing.T) {
+	s, store := newTestServer(t,
+		blog.Blog{Slug: "csharp", Uploaded: "2023-01-01T00:00:00+00:00", Tags: []string{"C#"}},
+		blog.Blog{Slug: "pipelines", Uploaded: "2023-02-01T00:00:00+00:00", Tags: []string{"CI/CD"}},
+	)
+	if err := store.PutMarkdown(context.Background(), "csharp", []byte("# C#\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The routes as the site serves them.
+	r := mux.NewRouter().UseEncodedPath()
+	r.HandleFunc("/tags", s.Tags)
+	r.HandleFunc("/tags/{tag}", s.Tag)
+	r.HandleFunc("/tags/{tag}/feed.xml", s.RSS)
+	r.HandleFunc("/blog/{slug}", s.Show)
+
+	tests := map[string]struct {
+		target     string
+		wantInBody []string
+	}{
+		"tags":       {target: "/tags", wantInBody: []string{`href="/tags/C%23"`, `href="/tags/CI%2FCD"`}},
+		"blog":       {target: "/blog/csharp", wantInBody: []string{`href="/tags/C%23"`}},
+		"hash":       {target: "/tags/C%23", wantInBody: []string{"<strong>#C#</strong>", `href="/tags/C%23/feed.xml"`, `href="/blog/csharp"`}},
+		"slash":      {target: "/tags/CI%2FCD", wantInBody: []string{"<strong>#CI/CD</strong>", `href="/blog/pipelines"`}},
+		"slash feed": {target: "/tags/CI%2FCD/feed.xml", wantInBody: []string{"/blog/pipelines"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			body := rec.Body.String()
+			for _, want := range tc.wantInBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q\n%s", want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestSeries(t *testing.T) {
+	blogs := []blog.Blog{
+		{Slug: "part_two", Title: "Part Two", Uploaded: "2023-01-01T00:00:00+00:00", Series: "Building a Blog", SeriesPart: 2},
+		{Slug: "part_one", Title: "Part One", Uploaded: "2023-02-01T00:00:00+00:00", Series: "Building a Blog", SeriesPart: 1},
+		{Slug: "part_three", Title: "Part Three", Uploaded: "2023-03-01T00:00:00+00:00", Series: "Building a Blog", SeriesPart: 3},
+		{Slug: "standalone", Title: "Standalone", Uploaded: "2023-03-01T00:00:00+00:00"},
+	}
+
+	tests := map[string]struct {
+		handler    func(*Server) http.HandlerFunc
+		vars       map[string]string
+		wantStatus int
+		wantOrder  []string
+		wantInBody []string
+		notInBody  []string
+	}{
+		"series page": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Series },
+			vars:       map[string]string{"name": "building_a_blog"},
+			wantStatus: http.StatusOK,
+			wantOrder:  []string{`href="/blog/part_one"`, `href="/blog/part_two"`, `href="/blog/part_three"`},
+			wantInBody: []string{
+				"<title>Building a Blog | 😄 warrenb95</title>",
+				`<link rel="canonical" href="http://example.com/series/building_a_blog" />`,
+				"A series in 3 parts",
+			},
+			notInBody: []string{`href="/blog/standalone"`},
+		},
+		"unknown series": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Series },
+			vars:       map[string]string{"name": "nope"},
+			wantStatus: http.StatusNotFound,
+			wantInBody: []string{"There&#39;s no series by that name."},
+		},
+		"middle part": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Show },
+			vars:       map[string]string{"slug": "part_two"},
+			wantStatus: http.StatusOK,
+			wantInBody: []string{
+				"Part 2 of 3 in",
+				`<a href="/series/building_a_blog" class="alert-link">Building a Blog</a>`,
+				`<a href="/blog/part_one" class="btn btn-outline-primary" rel="prev">&larr; Part One</a>`,
+				`<a href="/blog/part_three" class="btn btn-outline-primary" rel="next">Part Three &rarr;</a>`,
+			},
+		},
+		"first part": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Show },
+			vars:       map[string]string{"slug": "part_one"},
+			wantStatus: http.StatusOK,
+			wantInBody: []string{"Part 1 of 3 in", `rel="next"`},
+			notInBody:  []string{`rel="prev"`},
+		},
+		"not in a series": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Show },
+			vars:       map[string]string{"slug": "standalone"},
+			wantStatus: http.StatusOK,
+			notInBody:  []string{"/series/", `rel="prev"`, `rel="next"`},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, store := newTestServer(t, blogs...)
+			for _, b := range blogs {
+				if err := store.PutMarkdown(context.Background(), b.Slug, []byte("# "+b.Title+"\n")); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = mux.SetURLVars(req, tc.vars)
+			rec := httptest.NewRecorder()
+			tc.handler(s)(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			body := rec.Body.String()
+			assertOrder(t, body, tc.wantOrder)
+			for _, want := range tc.wantInBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q\n%s", want, body)
+				}
+			}
+			for _, unwanted := range tc.notInBody {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body contains %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestShowRelated(t *testing.T) {
+	s, store := newTestServer(t,
+		blog.Blog{Slug: "htmx_cards", Title: "Loading cards with htmx", Tags: []string{"htmx"}, Uploaded: "2023-01-01T00:00:00+00:00"},
+		blog.Blog{Slug: "htmx_search", Title: "Searching with htmx", Summary: "Live search.", Tags: []string{"htmx"}, Uploaded: "2023-02-01T00:00:00+00:00"},
+		blog.Blog{Slug: "aws", Title: "Deploying to AWS", Tags: []string{"aws"}, Uploaded: "2023-03-01T00:00:00+00:00"},
+		blog.Blog{Slug: "htmx_draft", Title: "More htmx", Tags: []string{"htmx"}, Draft: true},
+	)
+	for slug, md := range map[string]string{
+		"htmx_cards":  "Cards are swapped in with hx-get.",
+		"htmx_search": "Results are swapped in with hx-get.",
+		"aws":         "Zip it and upload it.",
+	} {
+		if err := store.PutMarkdown(context.Background(), slug, []byte(md)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/blog/htmx_cards", nil)
+	req = mux.SetURLVars(req, map[string]string{"slug": "htmx_cards"})
+	rec := httptest.NewRecorder()
+	s.Show(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"Related blogs", `href="/blog/htmx_search" class="link-primary link-underline-opacity-0 stretched-link">Searching with htmx</a>`, "Live search."} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body doesn't contain %q\n%s", want, body)
+		}
+	}
+	for _, unwanted := range []string{`href="/blog/aws"`, `href="/blog/htmx_draft"`} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("body contains %q", unwanted)
+		}
+	}
+
+	// It's shown from the index and the cache after that, without going to the stores.
+	store.Err = errors.New("dynamodb is down")
+	rec = httptest.NewRecorder()
+	s.Show(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Related blogs") {
+		t.Errorf("status = %d without the stores, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestHighlightCSS(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	s.HighlightCSS(rec, httptest.NewRequest(http.MethodGet, "/highlight.css", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/css; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, ".chroma .kn {") || !strings.Contains(body, ".code-title {") {
+		t.Errorf("body doesn't style the code blocks\n%s", body)
+	}
+}
@@ -0,0 +1,248 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	nhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/warrenb95/website/internal/blog"
+	"github.com/warrenb95/website/internal/search"
+	"github.com/warrenb95/website/internal/transform"
+)
+
+// contentIndex is worked out from every visible blog and its markdown. It's built off the request path,
+// see WatchIndex, and isn't changed once built.
+type contentIndex struct {
+	// fingerprint identifies the blogs and markdown it was built from.
+	fingerprint uint64
+	blogs       map[string]blog.Blog
+	// versions are the versions of each blog's markdown, empty for a blog without any, and text the
+	// text it renders to. A blog's text is only rendered again when its version changes.
+	versions map[string]string
+	text     map[string]string
+	search   *search.Index
+	// related are the slugs of each blog's related blogs, most related first.
+	related map[string][]string
+	// series are the parts of each series by its slug, in reading order.
+	series map[string][]blog.Blog
+}
+
+// relatedSize is how many related blogs are shown under a blog.
+const relatedSize = 3
+
+// indexTimeout bounds building the content index.
+const indexTimeout = 5 * time.Minute
+
+// currentIndex returns the last content index built. Only the requests before the first one's built
+// wait for it, the rest get the last one while WatchIndex keeps it up to date.
+func (s *Server) currentIndex(ctx context.Context) (*contentIndex, error) {
+	if idx := s.index.Load(); idx != nil {
+		return idx, nil
+	}
+	return s.refreshIndex(ctx)
+}
+
+// WatchIndex refreshes the content index every interval until ctx is done, failures are logged and
+// the last index kept.
+func (s *Server) WatchIndex(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.refreshIndex(ctx); err != nil && ctx.Err() == nil {
+			s.logger.WithError(err).Error("Failed to refresh the content index")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshIndex rebuilds the content index from the blogs as they are now. Callers refreshing it at the
+// same time share one rebuild.
+func (s *Server) refreshIndex(ctx context.Context) (*contentIndex, error) {
+	return s.indexBuilds.Do(ctx, "", s.buildIndex)
+}
+
+// buildIndex builds the content index, reusing the text of the last one's blogs whose markdown hasn't
+// changed. A blog that fails to index is logged and left as it was in the last index, or out of it.
+func (s *Server) buildIndex(ctx context.Context) (*contentIndex, error) {
+	logger := s.logger.WithContext(ctx)
+
+	blogs, err := s.listBlogs(ctx, logger, listQuery{})
+	if err != nil {
+		return nil, fmt.Errorf("listing blogs: %w", err)
+	}
+
+	last := s.index.Load()
+	if last == nil {
+		last = &contentIndex{}
+	}
+	idx := &contentIndex{
+		blogs:    make(map[string]blog.Blog, len(blogs)),
+		versions: make(map[string]string, len(blogs)),
+		text:     make(map[string]string, len(blogs)),
+	}
+	for _, b := range blogs {
+		version, text, err := s.indexText(ctx, b.Slug, last)
+		if err != nil {
+			logger.WithError(err).WithField("slug", b.Slug).Error("Failed to index blog")
+			if _, ok := last.blogs[b.Slug]; !ok {
+				continue
+			}
+			b, version, text = last.blogs[b.Slug], last.versions[b.Slug], last.text[b.Slug]
+		}
+		idx.blogs[b.Slug] = b
+		idx.versions[b.Slug] = version
+		idx.text[b.Slug] = text
+	}
+
+	idx.fingerprint = fingerprint(idx.blogs, idx.versions)
+	if last.search != nil && last.fingerprint == idx.fingerprint {
+		return last, nil
+	}
+
+	docs := make([]search.Document, 0, len(idx.blogs))
+	idx.series = make(map[string][]blog.Blog)
+	for _, listed := range blogs {
+		b, ok := idx.blogs[listed.Slug]
+		if !ok {
+			continue
+		}
+		docs = append(docs, search.Document{
+			Slug:    b.Slug,
+			Title:   b.Title,
+			Summary: b.Summary,
+			Tags:    b.Tags,
+			Body:    idx.text[b.Slug],
+		})
+		if b.Series != "" {
+			slug := blog.SeriesSlug(b.Series)
+			idx.series[slug] = append(idx.series[slug], b)
+		}
+	}
+	idx.search = search.New(docs)
+	idx.related = idx.search.Related(relatedSize)
+	for _, parts := range idx.series {
+		blog.SortParts(parts)
+	}
+	s.index.Store(idx)
+
+	logger.WithField("blogs", len(docs)).Info("Built content index")
+
+	return idx, nil
+}
+
+// indexText returns the version of the blog's markdown and its text, taking the text from the last
+// index when the markdown hasn't changed since.
+func (s *Server) indexText(ctx context.Context, slug string, last *contentIndex) (string, string, error) {
+	version, err := s.markdownVersion(ctx, slug)
+	if err != nil {
+		return "", "", err
+	}
+	if text, ok := last.text[slug]; ok && last.versions[slug] == version {
+		return version, text, nil
+	}
+
+	text, err := s.plainText(ctx, slug)
+	if err != nil {
+		return "", "", err
+	}
+	return version, text, nil
+}
+
+// markdownVersion returns the version of the blog's markdown, empty if it has none.
+func (s *Server) markdownVersion(ctx context.Context, slug string) (string, error) {
+	version, err := s.content.MarkdownVersion(ctx, slug)
+	if errors.Is(err, blog.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("getting blog %q content version: %w", slug, err)
+	}
+	return version, nil
+}
+
+// plainText returns the text of the blog's rendered markdown, empty if it has none.
+func (s *Server) plainText(ctx context.Context, slug string) (string, error) {
+	md, err := s.content.GetMarkdown(ctx, slug)
+	if errors.Is(err, blog.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("getting blog %q content: %w", slug, err)
+	}
+
+	_, body, err := blog.ParseFrontMatter(md)
+	if err != nil {
+		return "", fmt.Errorf("parsing blog %q front matter: %w", slug, err)
+	}
+	html, _, err := s.renderMarkdown(body, "")
+	if err != nil {
+		return "", fmt.Errorf("rendering blog %q: %w", slug, err)
+	}
+
+	return htmlText(html)
+}
+
+// htmlText returns the text in html with its whitespace collapsed, leaving out the heading permalinks.
+func htmlText(html template.HTML) (string, error) {
+	body := &nhtml.Node{Type: nhtml.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := nhtml.ParseFragment(strings.NewReader(string(html)), body)
+	if err != nil {
+		return "", fmt.Errorf("parsing html: %w", err)
+	}
+
+	var buf bytes.Buffer
+	var walk func(n *nhtml.Node)
+	walk = func(n *nhtml.Node) {
+		if transform.IsHeadingAnchor(n) {
+			return
+		}
+		if n.Type == nhtml.TextNode {
+			buf.WriteString(n.Data)
+			buf.WriteByte(' ')
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+
+	return strings.Join(strings.Fields(buf.String()), " "), nil
+}
+
+// fingerprint hashes the blogs' metadata and the versions of their markdown, in any order.
+func fingerprint(blogs map[string]blog.Blog, versions map[string]string) uint64 {
+	var sum uint64
+	for slug, b := range blogs {
+		h := fnv.New64a()
+		h.Write([]byte(versions[slug]))
+		sum += blogVersion(b) ^ h.Sum64()
+	}
+	return sum + uint64(len(blogs))
+}
+
+// blogVersion hashes the blog's metadata.
+func blogVersion(b blog.Blog) uint64 {
+	h := fnv.New64a()
+	for _, f := range []string{b.Slug, b.Title, b.Summary, b.Uploaded, b.Updated, strings.Join(b.Tags, ","), b.Series, strconv.Itoa(b.SeriesPart), strconv.FormatBool(b.Draft)} {
+		h.Write([]byte(f))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/warrenb95/website/internal/config"
+	"github.com/warrenb95/website/internal/storage/memory"
+)
+
+func TestLogger(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	store := memory.NewStore()
+	s := NewServer(config.Default(), store, store, nil, logger)
+
+	var called bool
+	h := s.Logger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/about", nil))
+
+	if !called {
+		t.Fatal("wrapped handler wasn't called")
+	}
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusTeapot)
+	}
+
+	entries := hook.AllEntries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d log entries, want 2", len(entries))
+	}
+	for i, msg := range []string{"Request start", "Request end"} {
+		if entries[i].Message != msg {
+			t.Errorf("entry %d message = %q, want %q", i, entries[i].Message, msg)
+		}
+		if got := entries[i].Data["endpoint"]; got == nil || got.(interface{ String() string }).String() != "/about" {
+			t.Errorf("entry %d endpoint = %v, want /about", i, got)
+		}
+	}
+}
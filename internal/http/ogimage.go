@@ -0,0 +1,85 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warrenb95/website/internal/blog"
+	"github.com/warrenb95/website/internal/ogimage"
+)
+
+// OGImage serves the {slug} blog's generated preview image, drawing it the first time it's asked for.
+func (s *Server) OGImage(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		slug := mux.Vars(r)["slug"]
+		notFound := newError(http.StatusNotFound, "This image doesn't exist.", fmt.Errorf("preview image %q: %w", slug, blog.ErrNotFound))
+		if !blog.ValidSlug(slug) {
+			return notFound
+		}
+
+		b, err := s.blogs.Get(r.Context(), slug)
+		if errors.Is(err, blog.ErrNotFound) || (err == nil && !s.visible(b)) {
+			return notFound
+		}
+		if err != nil {
+			return fmt.Errorf("getting blog %q: %w", slug, err)
+		}
+		b.DefaultTitle()
+
+		card := ogCard(b)
+		etag := `"` + card.Version() + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+
+		name := ogImageName(slug)
+		logger := s.logger.WithContext(r.Context()).WithField("image", name)
+		img, err := s.content.GetImage(r.Context(), name)
+		if err != nil && !errors.Is(err, blog.ErrNotFound) {
+			logger.WithError(err).Error("Failed to get cached preview image")
+		}
+		// It's drawn again over the old one when the card's changed.
+		if err != nil || ogimage.VersionOf(img) != card.Version() {
+			img, err = ogimage.Render(card)
+			if err != nil {
+				return fmt.Errorf("rendering preview image for %q: %w", slug, err)
+			}
+			if err := s.content.PutImage(r.Context(), name, img, "image/png"); err != nil {
+				// It can be drawn again next time.
+				logger.WithError(err).Error("Failed to cache preview image")
+			}
+		}
+
+		w.Header().Set("Content-Type", "image/png")
+		_, err = w.Write(img)
+		return err
+	})
+}
+
+func ogCard(b blog.Blog) ogimage.Card {
+	card := ogimage.Card{Title: b.Title, Site: siteTitle, Tags: b.Tags}
+	if t, err := b.UploadedAt(); err == nil {
+		card.Date = t.Format("2 January 2006")
+	}
+	return card
+}
+
+// ogImageName is the name the blog's preview image is cached under in the content store.
+// There's one per blog, it's replaced when the blog's edited.
+func ogImageName(slug string) string {
+	return "og_" + slug + ".png"
+}
+
+// ogImageURL is the path of the blog's preview image, its thumbnail if it has one.
+func ogImageURL(b blog.Blog) string {
+	if b.ThumbnailPath != "" {
+		return b.ThumbnailPath
+	}
+	return "/og/" + b.Slug + ".png"
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warrenb95/website/internal/blog"
+	"github.com/warrenb95/website/internal/ogimage"
+)
+
+func TestOGImage(t *testing.T) {
+	htmx := blog.Blog{Slug: "htmx_and_go", Title: "htmx & Go", Uploaded: "2023-11-03T20:00:00+00:00", Tags: []string{"go"}}
+	draft := blog.Blog{Slug: "draft", Draft: true}
+
+	current, err := ogimage.Render(ogCard(htmx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := htmx
+	old.Title = "htmx and Go"
+	stale, err := ogimage.Render(ogCard(old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag := `"` + ogCard(htmx).Version() + `"`
+
+	tests := map[string]struct {
+		slug        string
+		cached      []byte
+		ifNoneMatch string
+		wantStatus  int
+		wantBody    []byte
+	}{
+		"rendered": {
+			slug:       "htmx_and_go",
+			wantStatus: http.StatusOK,
+		},
+		"cached": {
+			slug:       "htmx_and_go",
+			cached:     current,
+			wantStatus: http.StatusOK,
+			wantBody:   current,
+		},
+		"stale": {
+			slug:       "htmx_and_go",
+			cached:     stale,
+			wantStatus: http.StatusOK,
+		},
+		"not cached properly": {
+			slug:       "htmx_and_go",
+			cached:     []byte("not a png"),
+			wantStatus: http.StatusOK,
+		},
+		"not modified": {
+			slug:        "htmx_and_go",
+			ifNoneMatch: etag,
+			wantStatus:  http.StatusNotModified,
+		},
+		"draft": {
+			slug:       "draft",
+			wantStatus: http.StatusNotFound,
+		},
+		"missing": {
+			slug:       "does_not_exist",
+			wantStatus: http.StatusNotFound,
+		},
+		"invalid slug": {
+			slug:       "..",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, store := newTestServer(t, htmx, draft)
+			imageName := ogImageName(htmx.Slug)
+			if tc.cached != nil {
+				if err := store.PutImage(context.Background(), imageName, tc.cached, "image/png"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/og/"+tc.slug+".png", nil)
+			req = mux.SetURLVars(req, map[string]string{"slug": tc.slug})
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			s.OGImage(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			if got := rec.Header().Get("ETag"); got != etag {
+				t.Errorf("etag = %q, want %q", got, etag)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "image/png" {
+				t.Errorf("content type = %q", got)
+			}
+
+			if tc.wantBody != nil {
+				if !bytes.Equal(rec.Body.Bytes(), tc.wantBody) {
+					t.Errorf("body = %q, want the cached image", rec.Body.Bytes())
+				}
+				return
+			}
+			img, err := png.Decode(bytes.NewReader(rec.Body.Bytes()))
+			if err != nil {
+				t.Fatalf("not a png: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != ogimage.Width || b.Dy() != ogimage.Height {
+				t.Errorf("size = %dx%d", b.Dx(), b.Dy())
+			}
+			if got := ogimage.VersionOf(rec.Body.Bytes()); got != ogCard(htmx).Version() {
+				t.Errorf("drew version %q, want %q", got, ogCard(htmx).Version())
+			}
+			cached, err := store.GetImage(context.Background(), imageName)
+			if err != nil || !bytes.Equal(cached, rec.Body.Bytes()) {
+				t.Errorf("image wasn't cached: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	mdhtml "github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+
+	"github.com/warrenb95/website/internal/blog"
+	"github.com/warrenb95/website/internal/highlight"
+	"github.com/warrenb95/website/internal/transform"
+)
+
+// renderedBlog is a blog's rendered markdown and what's worked out with it. They're cached and shared
+// between requests, so they mustn't be changed.
+type renderedBlog struct {
+	Content template.HTML
+	// Title and CanonicalURL are the front matter's, empty when it doesn't set them.
+	Title        string
+	CanonicalURL string
+	// Markdown is the blog's markdown without its front matter.
+	Markdown []byte
+	Headings []transform.Heading
+	// TOC is the front matter's toc, whether to show the table of contents whatever its length.
+	TOC *bool
+}
+
+// size is roughly how much memory the rendered blog takes up in the cache.
+func (r renderedBlog) size() int {
+	n := len(r.Content) + len(r.Title) + len(r.CanonicalURL) + len(r.Markdown)
+	for _, h := range r.Headings {
+		n += len(h.ID) + len(h.Text)
+	}
+	return n
+}
+
+// renderBlog renders the blog's markdown into its Content.
+// Titles and canonical URLs in the front matter win over the stored ones, in case the blog store hasn't
+// been synced yet. Links to the site's own pages are made absolute with base, for pages read off the
+// site like feeds.
+//
+// Rendered blogs are cached by the version of their markdown in the content index, so an edit shows
+// once the index is refreshed, or straight away when the blog's purged from the cache.
+func (s *Server) renderBlog(ctx context.Context, b *blog.Blog, base string) (renderedBlog, error) {
+	slug := b.Slug
+	var rendered renderedBlog
+	version, err := s.contentVersion(ctx, slug)
+	if err != nil {
+		// It can still be rendered, but not cached without knowing when it changes.
+		s.logger.WithContext(ctx).WithError(err).WithField("slug", slug).Warn("Rendering blog without caching it")
+		rendered, err = s.loadBlog(ctx, slug, base)
+	} else {
+		rendered, err = s.rendered.GetOrLoad(ctx, renderedKey(slug, version, base), func(ctx context.Context) (renderedBlog, error) {
+			return s.loadBlog(ctx, slug, base)
+		})
+	}
+	if err != nil {
+		return renderedBlog{}, err
+	}
+
+	if rendered.Title != "" {
+		b.Title = rendered.Title
+	}
+	if rendered.CanonicalURL != "" {
+		b.CanonicalURL = rendered.CanonicalURL
+	}
+	b.Content = rendered.Content
+
+	return rendered, nil
+}
+
+// renderedKey is the key of the blog's markdown at version rendered with base in the cache, it starts
+// with the blog's slug.
+func renderedKey(slug, version, base string) string {
+	return slug + "\x00" + version + "\x00" + base
+}
+
+// contentVersion returns the version of the blog's markdown in the content index, or in the content
+// store for a blog that isn't indexed yet.
+func (s *Server) contentVersion(ctx context.Context, slug string) (string, error) {
+	if idx := s.index.Load(); idx != nil {
+		if version, ok := idx.versions[slug]; ok {
+			return version, nil
+		}
+	}
+	return s.markdownVersion(ctx, slug)
+}
+
+// loadBlog gets the blog's markdown and renders it.
+func (s *Server) loadBlog(ctx context.Context, slug, base string) (renderedBlog, error) {
+	md, err := s.content.GetMarkdown(ctx, slug)
+	if err != nil {
+		return renderedBlog{}, fmt.Errorf("getting blog %q content: %w", slug, err)
+	}
+
+	fm, body, err := blog.ParseFrontMatter(md)
+	if err != nil {
+		return renderedBlog{}, fmt.Errorf("parsing blog %q front matter: %w", slug, err)
+	}
+
+	rendered := renderedBlog{Title: fm.Title, CanonicalURL: fm.CanonicalURL, Markdown: body, TOC: fm.TOC}
+	rendered.Content, rendered.Headings, err = s.renderMarkdown(body, base)
+	if err != nil {
+		return renderedBlog{}, fmt.Errorf("rendering blog %q: %w", slug, err)
+	}
+
+	return rendered, nil
+}
+
+// renderMarkdown renders md to HTML with highlighted code, sanitizes it so raw HTML in the markdown
+// can't run script, then runs the site's transformers over it. It returns the headings for a table of
+// contents.
+func (s *Server) renderMarkdown(md []byte, base string) (template.HTML, []transform.Heading, error) {
+	renderer := mdhtml.NewRenderer(mdhtml.RendererOptions{
+		Flags:          mdhtml.CommonFlags,
+		RenderNodeHook: s.renderCode,
+	})
+	p := parser.New()
+	p.Opts.ParserHook = parseFence
+	output := markdown.ToHTML(md, p, renderer)
+
+	doc, err := transform.Parse(bytes.NewReader(output))
+	if err != nil {
+		return "", nil, err
+	}
+	// Before the transformers so they can add what the policy doesn't allow, like link targets.
+	s.sanitizer.Sanitize(doc.Body)
+	doc.Base, doc.Host = base, s.siteHost(base)
+	s.transformer.Apply(doc)
+
+	var buf bytes.Buffer
+	if err := doc.Render(&buf); err != nil {
+		return "", nil, err
+	}
+
+	return template.HTML(buf.String()), doc.Headings, nil
+}
+
+// siteHost returns the host of base, or of the configured site URL without one.
+func (s *Server) siteHost(base string) string {
+	if base == "" {
+		base = s.config.SiteURL
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// HighlightCSS serves the stylesheet for the highlighted code blocks in the configured theme.
+func (s *Server) HighlightCSS(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		var buf bytes.Buffer
+		if err := s.highlighter.CSS(&buf); err != nil {
+			return fmt.Errorf("writing highlight css: %w", err)
+		}
+
+		w.Header().Set("Content-Type", "text/css; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		_, err := buf.WriteTo(w)
+		return err
+	})
+}
+
+// renderCode renders code blocks highlighted, gomarkdown renders everything else.
+func (s *Server) renderCode(w io.Writer, node ast.Node, _ bool) (ast.WalkStatus, bool) {
+	code, ok := node.(*ast.CodeBlock)
+	if !ok {
+		return ast.GoToNext, false
+	}
+
+	var buf bytes.Buffer
+	if err := s.highlighter.Block(&buf, string(code.Literal), highlight.ParseInfo(string(code.Info))); err != nil {
+		// Let gomarkdown render it plain.
+		s.logger.WithError(err).Error("Failed to highlight code block")
+		return ast.GoToNext, false
+	}
+	buf.WriteTo(w)
+
+	return ast.GoToNext, true
+}
+
+// parseFence parses the fenced code blocks gomarkdown doesn't, those with more than a language
+// in their info string like ```go {3-5} title="main.go". Like gomarkdown, it needs a closing fence.
+func parseFence(data []byte) (ast.Node, []byte, int) {
+	end := bytes.IndexByte(data, '\n')
+	if end < 0 {
+		return nil, nil, 0
+	}
+	open := bytes.TrimLeft(data[:end], " ")
+	if end-len(open) > 3 || len(open) == 0 || (open[0] != '`' && open[0] != '~') {
+		return nil, nil, 0
+	}
+	n := len(open) - len(bytes.TrimLeft(open, string(open[:1])))
+	marker, info := open[:n], bytes.TrimSpace(open[n:])
+	if n < 3 || !bytes.ContainsAny(info, " \t") || (marker[0] == '`' && bytes.ContainsRune(info, '`')) {
+		return nil, nil, 0
+	}
+
+	start := end + 1
+	for i := start; i < len(data); {
+		lineEnd, next := len(data), len(data)
+		if j := bytes.IndexByte(data[i:], '\n'); j >= 0 {
+			lineEnd, next = i+j, i+j+1
+		}
+		line := data[i:lineEnd]
+		if len(line)-len(bytes.TrimLeft(line, " ")) <= 3 && bytes.Equal(bytes.TrimSpace(line), marker) {
+			code := &ast.CodeBlock{
+				IsFenced:    true,
+				Info:        info,
+				FenceChar:   marker[0],
+				FenceLength: n,
+			}
+			code.Literal = data[start:i]
+			return code, nil, next
+		}
+		i = next
+	}
+
+	return nil, nil, 0
+}
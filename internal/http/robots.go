@@ -0,0 +1,62 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// aiTrainingCrawlers are the user agents of the crawlers collecting AI training data.
+var aiTrainingCrawlers = []string{
+	"GPTBot",
+	"ClaudeBot",
+	"anthropic-ai",
+	"Google-Extended",
+	"Applebot-Extended",
+	"CCBot",
+	"Bytespider",
+	"meta-externalagent",
+	"cohere-ai",
+	"Diffbot",
+}
+
+// Robots serves robots.txt from the robots config.
+func (s *Server) Robots(w http.ResponseWriter, r *http.Request) {
+	cfg := s.config.Robots
+
+	var b strings.Builder
+	blocked := append([]string{}, cfg.Block...)
+	if cfg.BlockAITraining {
+		blocked = append(blocked, aiTrainingCrawlers...)
+	}
+	if len(blocked) > 0 {
+		writeRobotsGroup(&b, blocked, []string{"/"})
+	}
+
+	if len(cfg.Allow) > 0 {
+		// Only the allowed crawlers get in, everyone else is blocked.
+		writeRobotsGroup(&b, cfg.Allow, cfg.Disallow)
+		writeRobotsGroup(&b, []string{"*"}, []string{"/"})
+	} else {
+		writeRobotsGroup(&b, []string{"*"}, cfg.Disallow)
+	}
+
+	fmt.Fprintf(&b, "Sitemap: %s\n", s.absURL(r, "/sitemap.xml"))
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, b.String())
+}
+
+// writeRobotsGroup writes the rules for the user agents, an empty Disallow allows everything.
+func writeRobotsGroup(b *strings.Builder, userAgents, disallow []string) {
+	for _, ua := range userAgents {
+		fmt.Fprintf(b, "User-agent: %s\n", ua)
+	}
+	if len(disallow) == 0 {
+		b.WriteString("Disallow:\n")
+	}
+	for _, path := range disallow {
+		fmt.Fprintf(b, "Disallow: %s\n", path)
+	}
+	b.WriteString("\n")
+}
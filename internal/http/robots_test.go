@@ -0,0 +1,69 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/warrenb95/website/internal/config"
+)
+
+func TestRobots(t *testing.T) {
+	tests := map[string]struct {
+		robots     config.Robots
+		siteURL    string
+		want       string
+		wantInBody []string
+	}{
+		"default": {
+			robots: config.Default().Robots,
+			want: "User-agent: *\nDisallow: /blogs\nDisallow: /search\nDisallow: /api/\n\n" +
+				"Sitemap: http://example.com/sitemap.xml\n",
+		},
+		"allow everything": {
+			siteURL: "https://warrenb95.dev",
+			want:    "User-agent: *\nDisallow:\n\nSitemap: https://warrenb95.dev/sitemap.xml\n",
+		},
+		"block crawlers": {
+			robots: config.Robots{Block: []string{"BadBot"}, Disallow: []string{"/api/"}},
+			want: "User-agent: BadBot\nDisallow: /\n\n" +
+				"User-agent: *\nDisallow: /api/\n\n" +
+				"Sitemap: http://example.com/sitemap.xml\n",
+		},
+		"only allowed crawlers": {
+			robots: config.Robots{Allow: []string{"Googlebot", "Bingbot"}},
+			want: "User-agent: Googlebot\nUser-agent: Bingbot\nDisallow:\n\n" +
+				"User-agent: *\nDisallow: /\n\n" +
+				"Sitemap: http://example.com/sitemap.xml\n",
+		},
+		"block ai training": {
+			robots:     config.Robots{BlockAITraining: true},
+			wantInBody: []string{"User-agent: GPTBot\n", "User-agent: CCBot\n", "Disallow: /\n\nUser-agent: *\nDisallow:\n"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, _ := newTestServer(t)
+			s.config.Robots = tc.robots
+			s.config.SiteURL = tc.siteURL
+
+			rec := httptest.NewRecorder()
+			s.Robots(rec, httptest.NewRequest(http.MethodGet, "/robots.txt", nil))
+
+			if got := rec.Header().Get("Content-Type"); got != "text/plain; charset=utf-8" {
+				t.Errorf("content type = %q", got)
+			}
+			body := rec.Body.String()
+			if tc.want != "" && body != tc.want {
+				t.Errorf("got\n%s\nwant\n%s", body, tc.want)
+			}
+			for _, want := range tc.wantInBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q\n%s", want, body)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/warrenb95/website/internal/search"
+)
+
+const (
+	// searchSize is how many results are on the search page.
+	searchSize = 20
+	// suggestionSize is how many results are suggested as a search is typed.
+	suggestionSize = 5
+	// maxQueryLength is the longest search in characters.
+	maxQueryLength = 100
+)
+
+// searchPage is the results of a search, there aren't any before something's searched for.
+type searchPage struct {
+	meta
+
+	Query   string
+	Results []searchResult
+	Total   int
+}
+
+type searchResult struct {
+	search.Result
+
+	Uploaded string
+	Tags     []string
+}
+
+// Search renders the blogs matching ?q=.
+func (s *Server) Search(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		return s.searchPage(w, r, "search.html", search.Query{Limit: searchSize})
+	})
+}
+
+// SearchSuggestions renders the few best matches for ?q= as it's typed, for htmx to show under the navbar's search box.
+func (s *Server) SearchSuggestions(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		return s.searchPage(w, r, "search-suggestions", search.Query{Prefix: true, Limit: suggestionSize})
+	})
+}
+
+func (s *Server) searchPage(w http.ResponseWriter, r *http.Request, name string, q search.Query) error {
+	// Keep the trailing space, it finishes the last word when searching as it's typed.
+	q.Text = strings.TrimLeft(r.URL.Query().Get("q"), " ")
+	if utf8.RuneCountInString(q.Text) > maxQueryLength {
+		return newError(http.StatusBadRequest, fmt.Sprintf("Searches can't be longer than %d characters.", maxQueryLength), fmt.Errorf("search of %d bytes", len(q.Text)))
+	}
+
+	data := searchPage{Query: strings.TrimSpace(q.Text)}
+	data.Canonical = s.absURL(r, "/search")
+	data.PageTitle = "Search"
+	data.Description = fmt.Sprintf("Search the blogs by %s.", siteAuthor)
+	if data.Query != "" {
+		data.Canonical += "?q=" + url.QueryEscape(data.Query)
+		data.PageTitle = fmt.Sprintf("Search results for %q", data.Query)
+
+		idx, err := s.currentIndex(r.Context())
+		if err != nil {
+			return newError(http.StatusInternalServerError, "Search isn't working right now.", fmt.Errorf("loading content index: %w", err))
+		}
+
+		var results []search.Result
+		results, data.Total = idx.search.Search(q)
+		for _, res := range results {
+			b := idx.blogs[res.Slug]
+			result := searchResult{Result: res, Tags: b.Tags}
+			if uploaded, err := b.UploadedAt(); err == nil {
+				result.Uploaded = uploaded.Format(time.DateOnly)
+			}
+			data.Results = append(data.Results, result)
+		}
+	}
+
+	if err := s.templates.Execute(w, name, data); err != nil {
+		return fmt.Errorf("executing %s template: %w", name, err)
+	}
+	return nil
+}
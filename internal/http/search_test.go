@@ -0,0 +1,230 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/warrenb95/website/internal/blog"
+	"github.com/warrenb95/website/internal/storage/memory"
+)
+
+func TestSearch(t *testing.T) {
+	blogs := []blog.Blog{
+		{Slug: "htmx", Title: "Infinite scroll", Uploaded: "2023-11-03T20:30:00+00:00", Tags: []string{"htmx"}},
+		{Slug: "deploy", Title: "Deploying to AWS", Uploaded: "2023-01-05T09:00:00+00:00", Summary: "Elastic Beanstalk."},
+		{Slug: "draft", Title: "Deploying a draft", Draft: true},
+	}
+	markdown := map[string]string{
+		"htmx":   "---\ntitle: Infinite scroll\n---\n# Cards\n\nCards are loaded with `hx-get` as you scroll.\n",
+		"deploy": "Zip the binary and **deploy** it.\n",
+	}
+
+	tests := map[string]struct {
+		handler    func(*Server) http.HandlerFunc
+		target     string
+		wantStatus int
+		wantOrder  []string
+		wantInBody []string
+		notInBody  []string
+	}{
+		"results": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Search },
+			target:     "/search?q=deployed",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{
+				`<title>Search results for &#34;deployed&#34; | 😄 warrenb95</title>`,
+				`<link rel="canonical" href="http://example.com/search?q=deployed" />`,
+				`value="deployed"`,
+				"1 result for",
+				`<a href="/blog/deploy" class="link-primary"><mark>Deploying</mark> to AWS</a>`,
+				"Zip the binary and <mark>deploy</mark> it.",
+				"2023-01-05",
+			},
+			notInBody: []string{`href="/blog/draft"`, `href="/blog/htmx"`},
+		},
+		"body matches": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Search },
+			target:     "/search?q=card",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{`href="/blog/htmx"`, "<mark>Cards</mark> <mark>Cards</mark> are loaded with hx-get as you scroll."},
+			notInBody:  []string{"title: Infinite scroll"},
+		},
+		"no results": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Search },
+			target:     "/search?q=rust",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{"0 results for", "Nothing matched"},
+		},
+		"no query": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Search },
+			target:     "/search",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{"<title>Search | 😄 warrenb95</title>"},
+			notInBody:  []string{"results for", "Nothing matched"},
+		},
+		"too long": {
+			handler:    func(s *Server) http.HandlerFunc { return s.Search },
+			target:     "/search?q=" + strings.Repeat("a", maxQueryLength+1),
+			wantStatus: http.StatusBadRequest,
+			wantInBody: []string{"Searches can&#39;t be longer than 100 characters."},
+		},
+		"suggestions as it's typed": {
+			handler:    func(s *Server) http.HandlerFunc { return s.SearchSuggestions },
+			target:     "/search/suggestions?q=infin",
+			wantStatus: http.StatusOK,
+			wantInBody: []string{`<a href="/blog/htmx" class="list-group-item list-group-item-action">`, "<mark>Infinite</mark> scroll"},
+			notInBody:  []string{"<html", "All 1 results"},
+		},
+		"no suggestions": {
+			handler:    func(s *Server) http.HandlerFunc { return s.SearchSuggestions },
+			target:     "/search/suggestions?q=",
+			wantStatus: http.StatusOK,
+			notInBody:  []string{"list-group"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, store := newTestServer(t, blogs...)
+			for slug, md := range markdown {
+				if err := store.PutMarkdown(context.Background(), slug, []byte(md)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			rec := httptest.NewRecorder()
+			tc.handler(s)(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			body := rec.Body.String()
+			assertOrder(t, body, tc.wantOrder)
+			for _, want := range tc.wantInBody {
+				if !strings.Contains(body, want) {
+					t.Errorf("body doesn't contain %q\n%s", want, body)
+				}
+			}
+			for _, unwanted := range tc.notInBody {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body contains %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+// indexedContent is a content store that counts the markdown got and fails for the blog in fail.
+type indexedContent struct {
+	*memory.Store
+	fail string
+	gets int
+}
+
+func (c *indexedContent) GetMarkdown(ctx context.Context, slug string) ([]byte, error) {
+	c.gets++
+	return c.Store.GetMarkdown(ctx, slug)
+}
+
+func (c *indexedContent) MarkdownVersion(ctx context.Context, slug string) (string, error) {
+	if slug == c.fail {
+		return "", errors.New("s3 is down")
+	}
+	return c.Store.MarkdownVersion(ctx, slug)
+}
+
+func TestRefreshIndex(t *testing.T) {
+	ctx := context.Background()
+	s, store := newTestServer(t, blog.Blog{Slug: "htmx", Title: "Infinite scroll", Uploaded: "2023-11-03T20:30:00+00:00"})
+	content := &indexedContent{Store: store}
+	s.content = content
+	logger, hook := test.NewNullLogger()
+	s.logger = logger
+	if err := store.PutMarkdown(ctx, "htmx", []byte("Cards are loaded as you scroll.\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	found := func(slug string) bool {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.Search(rec, httptest.NewRequest(http.MethodGet, "/search?q=templates", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		return strings.Contains(rec.Body.String(), `href="/blog/`+slug+`"`)
+	}
+	refresh := func() *contentIndex {
+		t.Helper()
+		idx, err := s.refreshIndex(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return idx
+	}
+
+	if found("templates") {
+		t.Fatal("found a blog before it was added")
+	}
+	first := s.index.Load()
+
+	if err := store.Put(ctx, blog.Blog{Slug: "templates", Title: "Go templates", Uploaded: "2023-11-04T20:30:00+00:00"}); err != nil {
+		t.Fatal(err)
+	}
+	if found("templates") {
+		t.Error("searching rebuilt the index")
+	}
+	if refresh() == first || !found("templates") {
+		t.Error("the index wasn't rebuilt with the new blog")
+	}
+
+	// Only the blogs whose markdown changed are rendered again.
+	second := s.index.Load()
+	content.gets = 0
+	if refresh() != second {
+		t.Error("the index was rebuilt when nothing changed")
+	}
+	if err := store.PutMarkdown(ctx, "htmx", []byte("The cards are rendered with Go templates.\n")); err != nil {
+		t.Fatal(err)
+	}
+	refresh()
+	if !found("htmx") {
+		t.Error("the index wasn't rebuilt when only the markdown changed")
+	}
+	if content.gets != 1 {
+		t.Errorf("got the markdown %d times, want once for htmx", content.gets)
+	}
+
+	// A blog that fails is kept as it was, and one that's never been indexed is left out.
+	content.fail = "htmx"
+	if err := store.Put(ctx, blog.Blog{Slug: "more_templates", Title: "More templates", Uploaded: "2023-11-05T20:30:00+00:00"}); err != nil {
+		t.Fatal(err)
+	}
+	refresh()
+	if !found("htmx") || !found("more_templates") {
+		t.Error("the index lost a blog that failed to refresh")
+	}
+	content.fail = "newest_templates"
+	if err := store.Put(ctx, blog.Blog{Slug: "newest_templates", Title: "Newest templates", Uploaded: "2023-11-06T20:30:00+00:00"}); err != nil {
+		t.Fatal(err)
+	}
+	refresh()
+	if found("newest_templates") {
+		t.Error("indexed a blog that failed")
+	}
+	var failures int
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "Failed to index blog" {
+			failures++
+		}
+	}
+	if failures != 2 {
+		t.Errorf("logged %d failures, want 2", failures)
+	}
+}
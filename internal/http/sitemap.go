@@ -0,0 +1,123 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warrenb95/website/internal/sitemap"
+)
+
+// sitemapSize is how many pages are in each sitemap before a sitemap index is served instead.
+const sitemapSize = 1000
+
+// Sitemap serves the sitemap, or a sitemap index of the numbered sitemaps once there are too many pages for one.
+func (s *Server) Sitemap(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		urls, err := s.sitemapURLs(r)
+		if err != nil {
+			return err
+		}
+		if len(urls) <= sitemapSize {
+			return writeSitemap(w, func(w io.Writer) error { return sitemap.WriteURLs(w, urls) })
+		}
+
+		var sitemaps []sitemap.Sitemap
+		for page := 1; (page-1)*sitemapSize < len(urls); page++ {
+			sm := sitemap.Sitemap{Loc: s.absURL(r, fmt.Sprintf("/sitemap-%d.xml", page))}
+			for _, u := range sitemapPage(urls, page) {
+				if u.LastMod.After(sm.LastMod) {
+					sm.LastMod = u.LastMod
+				}
+			}
+			sitemaps = append(sitemaps, sm)
+		}
+		return writeSitemap(w, func(w io.Writer) error { return sitemap.WriteIndex(w, sitemaps) })
+	})
+}
+
+// SitemapPage serves the {page} numbered sitemap from the sitemap index.
+func (s *Server) SitemapPage(w http.ResponseWriter, r *http.Request) {
+	s.handle(w, r, func(w http.ResponseWriter, r *http.Request) error {
+		urls, err := s.sitemapURLs(r)
+		if err != nil {
+			return err
+		}
+
+		p := mux.Vars(r)["page"]
+		page, err := strconv.Atoi(p)
+		if err != nil || page < 1 || len(sitemapPage(urls, page)) == 0 {
+			return newError(http.StatusNotFound, "This sitemap doesn't exist.", fmt.Errorf("no sitemap %q", p))
+		}
+
+		return writeSitemap(w, func(w io.Writer) error { return sitemap.WriteURLs(w, sitemapPage(urls, page)) })
+	})
+}
+
+// sitemapPage returns the urls in the page numbered sitemap, nil past the last one.
+func sitemapPage(urls []sitemap.URL, page int) []sitemap.URL {
+	// Compared before multiplying so huge pages can't overflow.
+	if page < 1 || page > (len(urls)+sitemapSize-1)/sitemapSize {
+		return nil
+	}
+	start := (page - 1) * sitemapSize
+	end := start + sitemapSize
+	if end > len(urls) {
+		end = len(urls)
+	}
+	return urls[start:end]
+}
+
+// sitemapURLs lists the pages search engines should index, from the same blogs as the index.
+func (s *Server) sitemapURLs(r *http.Request) ([]sitemap.URL, error) {
+	logger := s.logger.WithContext(r.Context())
+
+	blogs, err := s.listBlogs(r.Context(), logger, listQuery{})
+	if err != nil {
+		return nil, newError(http.StatusInternalServerError, "The blogs couldn't be loaded.", fmt.Errorf("listing blogs: %w", err))
+	}
+
+	urls := []sitemap.URL{{Loc: s.absURL(r, "/")}, {Loc: s.absURL(r, "/about")}}
+	for _, b := range blogs {
+		loc := s.absURL(r, "/blog/"+b.Slug)
+		if b.CanonicalURL != "" && b.CanonicalURL != loc {
+			// It's indexed where it was first published.
+			continue
+		}
+
+		u := sitemap.URL{Loc: loc}
+		if updated, err := b.UpdatedAt(); err == nil {
+			u.LastMod = updated
+		}
+		if b.ThumbnailPath != "" {
+			img := b.ThumbnailPath
+			if strings.HasPrefix(img, "/") {
+				img = s.absURL(r, img)
+			}
+			u.Images = []string{img}
+		}
+		if u.LastMod.After(urls[0].LastMod) {
+			// The index changes whenever a blog does.
+			urls[0].LastMod = u.LastMod
+		}
+		urls = append(urls, u)
+	}
+
+	return urls, nil
+}
+
+func writeSitemap(w http.ResponseWriter, write func(io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return fmt.Errorf("writing sitemap: %w", err)
+	}
+
+	w.Header().Set("Content-Type", sitemap.ContentType)
+	_, err := buf.WriteTo(w)
+	return err
+}
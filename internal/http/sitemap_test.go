@@ -0,0 +1,109 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/warrenb95/website/internal/blog"
+	"github.com/warrenb95/website/internal/sitemap"
+)
+
+func TestSitemap(t *testing.T) {
+	s, _ := newTestServer(t,
+		blog.Blog{Slug: "htmx_and_go", Uploaded: "2023-11-03T20:00:00+00:00", Updated: "2023-11-05T09:00:00+00:00", ThumbnailPath: "/static/htmx.png"},
+		blog.Blog{Slug: "deploying_to_aws", Uploaded: "2023-06-18T12:15:00+01:00", ThumbnailPath: "https://images.example.com/aws.png"},
+		blog.Blog{Slug: "cross_posted", Uploaded: "2023-01-05T09:00:00+00:00", CanonicalURL: "https://dev.to/warrenb95/cross-posted"},
+		blog.Blog{Slug: "draft", Uploaded: "2023-12-01T09:00:00+00:00", Draft: true},
+	)
+
+	rec := httptest.NewRecorder()
+	s.Sitemap(rec, httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); got != sitemap.ContentType {
+		t.Errorf("content type = %q", got)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"<loc>http://example.com/</loc>\n    <lastmod>2023-11-05T09:00:00Z</lastmod>",
+		"<loc>http://example.com/about</loc>",
+		"<loc>http://example.com/blog/htmx_and_go</loc>\n    <lastmod>2023-11-05T09:00:00Z</lastmod>",
+		"<image:loc>http://example.com/static/htmx.png</image:loc>",
+		"<loc>http://example.com/blog/deploying_to_aws</loc>\n    <lastmod>2023-06-18T12:15:00+01:00</lastmod>",
+		"<image:loc>https://images.example.com/aws.png</image:loc>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body doesn't contain %q\n%s", want, body)
+		}
+	}
+	for _, unwanted := range []string{"cross_posted", "draft"} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("body contains %q", unwanted)
+		}
+	}
+}
+
+func TestSitemapIndex(t *testing.T) {
+	var blogs []blog.Blog
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	// With the index and about pages that's one page over a single sitemap.
+	for i := 0; i < sitemapSize-1; i++ {
+		blogs = append(blogs, blog.Blog{
+			Slug:     fmt.Sprintf("blog_%04d", i),
+			Uploaded: start.AddDate(0, 0, i).Format(blog.UploadedLayout),
+		})
+	}
+	s, _ := newTestServer(t, blogs...)
+
+	rec := httptest.NewRecorder()
+	s.Sitemap(rec, httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"<sitemapindex",
+		"<loc>http://example.com/sitemap-1.xml</loc>",
+		"<loc>http://example.com/sitemap-2.xml</loc>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("index doesn't contain %q", want)
+		}
+	}
+	if strings.Contains(body, "sitemap-3.xml") {
+		t.Error("index has a third sitemap")
+	}
+
+	tests := map[string]struct {
+		page       string
+		wantStatus int
+		wantURLs   int
+	}{
+		"first":   {page: "1", wantStatus: http.StatusOK, wantURLs: sitemapSize},
+		"last":    {page: "2", wantStatus: http.StatusOK, wantURLs: 1},
+		"too far": {page: "3", wantStatus: http.StatusNotFound},
+		"zero":    {page: "0", wantStatus: http.StatusNotFound},
+		"huge":    {page: "9223372036854775807", wantStatus: http.StatusNotFound},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/sitemap-"+tc.page+".xml", nil)
+			req = mux.SetURLVars(req, map[string]string{"page": tc.page})
+			rec := httptest.NewRecorder()
+			s.SitemapPage(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if got := strings.Count(rec.Body.String(), "<url>"); got != tc.wantURLs {
+				t.Errorf("%d urls, want %d", got, tc.wantURLs)
+			}
+		})
+	}
+}
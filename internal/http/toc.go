@@ -0,0 +1,49 @@
+package http
+
+import (
+	"github.com/warrenb95/website/internal/transform"
+)
+
+// tocEntry is a heading in a table of contents with the headings under it.
+type tocEntry struct {
+	ID       string
+	Text     string
+	Children []*tocEntry
+}
+
+// buildTOC nests the headings under the ones before them with a lower level. Only depth levels are
+// kept, counting from the highest level heading. It returns the entries and how many there are in all.
+func buildTOC(headings []transform.Heading, depth int) ([]*tocEntry, int) {
+	top := 7
+	for _, h := range headings {
+		if h.Level < top {
+			top = h.Level
+		}
+	}
+
+	var toc []*tocEntry
+	count := 0
+	// parents are the latest entry at each level above the current heading.
+	var parents []*tocEntry
+	var levels []int
+	for _, h := range headings {
+		if h.Level >= top+depth {
+			continue
+		}
+		for len(levels) > 0 && levels[len(levels)-1] >= h.Level {
+			parents, levels = parents[:len(parents)-1], levels[:len(levels)-1]
+		}
+
+		entry := &tocEntry{ID: h.ID, Text: h.Text}
+		if len(parents) == 0 {
+			toc = append(toc, entry)
+		} else {
+			parent := parents[len(parents)-1]
+			parent.Children = append(parent.Children, entry)
+		}
+		parents, levels = append(parents, entry), append(levels, h.Level)
+		count++
+	}
+
+	return toc, count
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/warrenb95/website/internal/transform"
+)
+
+func TestRenderHeadings(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	md := "# Intro\n\n## Setup\n\n## Setup\n\n### `go run` it {#run}\n\n## 🚀\n"
+	html, headings, err := s.renderMarkdown([]byte(md), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []transform.Heading{
+		{Level: 1, ID: "intro", Text: "Intro"},
+		{Level: 2, ID: "setup", Text: "Setup"},
+		{Level: 2, ID: "setup_1", Text: "Setup"},
+		{Level: 3, ID: "run", Text: "go run it"},
+		{Level: 2, ID: "section", Text: "🚀"},
+	}
+	if !reflect.DeepEqual(headings, want) {
+		t.Errorf("got %+v, want %+v", headings, want)
+	}
+
+	for _, h := range want {
+		anchor := `<a class="heading-anchor" href="#` + h.ID + `" aria-label="Link to this section">#</a>`
+		if !strings.Contains(string(html), `id="`+h.ID+`"`) || !strings.Contains(string(html), anchor) {
+			t.Errorf("html doesn't anchor %q\n%s", h.ID, html)
+		}
+	}
+}
+
+func TestBuildTOC(t *testing.T) {
+	headings := []transform.Heading{
+		{Level: 2, ID: "a", Text: "A"},
+		{Level: 3, ID: "a1", Text: "A1"},
+		{Level: 4, ID: "a1i", Text: "A1i"},
+		{Level: 5, ID: "deep", Text: "Too deep"},
+		{Level: 3, ID: "a2", Text: "A2"},
+		{Level: 2, ID: "b", Text: "B"},
+		{Level: 4, ID: "b1", Text: "B1, skipping a level"},
+	}
+
+	toc, count := buildTOC(headings, 3)
+	want := []*tocEntry{
+		{ID: "a", Text: "A", Children: []*tocEntry{
+			{ID: "a1", Text: "A1", Children: []*tocEntry{{ID: "a1i", Text: "A1i"}}},
+			{ID: "a2", Text: "A2"},
+		}},
+		{ID: "b", Text: "B", Children: []*tocEntry{{ID: "b1", Text: "B1, skipping a level"}}},
+	}
+	if !reflect.DeepEqual(toc, want) {
+		t.Errorf("got %+v, want %+v", toc, want)
+	}
+	if count != 6 {
+		t.Errorf("count = %d, want 6", count)
+	}
+
+	toc, count = buildTOC(headings, 1)
+	if len(toc) != 2 || toc[0].Children != nil || count != 2 {
+		t.Errorf("depth 1 got %d entries, want only the 2 top level ones", count)
+	}
+
+	if toc, count := buildTOC(nil, 3); toc != nil || count != 0 {
+		t.Errorf("no headings got %+v", toc)
+	}
+}
@@ -0,0 +1,257 @@
+// Package ogimage draws the preview cards shown when a blog without a thumbnail is shared.
+package ogimage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// The size OpenGraph and Twitter recommend for large preview images.
+const (
+	Width  = 1200
+	Height = 630
+)
+
+const (
+	margin = 80
+	// maxTitleLines is how many lines the title can wrap over before it's shrunk.
+	maxTitleLines = 3
+	maxTags       = 4
+)
+
+// Colours from the site's Bootstrap dark theme.
+var (
+	background = color.RGBA{0x21, 0x25, 0x29, 0xff}
+	primary    = color.RGBA{0x0d, 0x6e, 0xfd, 0xff}
+	light      = color.RGBA{0xf8, 0xf9, 0xfa, 0xff}
+	muted      = color.RGBA{0xad, 0xb5, 0xbd, 0xff}
+	chip       = color.RGBA{0x34, 0x3a, 0x40, 0xff}
+)
+
+var (
+	bold    = mustParse(gobold.TTF)
+	regular = mustParse(goregular.TTF)
+)
+
+func mustParse(ttf []byte) *opentype.Font {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		panic(fmt.Sprintf("parsing bundled font: %v", err))
+	}
+	return f
+}
+
+// Card is what's drawn on a preview image.
+type Card struct {
+	Title string
+	// Date is shown as it's given, e.g. "3 November 2023".
+	Date string
+	Site string
+	Tags []string
+}
+
+// Version hashes the card, it changes whenever what's drawn would.
+func (c Card) Version() string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%q %q %q %q", c.Title, c.Date, c.Site, c.Tags)
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+// Render draws the card as a Width x Height PNG, with the card's Version in a text chunk.
+func Render(c Card) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	// An accent bar down the left edge.
+	draw.Draw(img, image.Rect(0, 0, 16, Height), image.NewUniform(primary), image.Point{}, draw.Src)
+
+	// The largest title size that fits, down to a size that always will by cutting it short.
+	var (
+		face  font.Face
+		lines []string
+		size  float64
+		err   error
+	)
+	for _, size = range []float64{72, 60, 50, 42} {
+		face, err = newFace(bold, size)
+		if err != nil {
+			return nil, err
+		}
+		lines = wrap(face, c.Title, Width-2*margin)
+		if len(lines) <= maxTitleLines {
+			break
+		}
+	}
+	if len(lines) > maxTitleLines {
+		lines = lines[:maxTitleLines]
+		lines[maxTitleLines-1] = ellipsis(face, lines[maxTitleLines-1], Width-2*margin)
+	}
+
+	lineHeight := int(size * 1.25)
+	y := margin + int(size)
+	for _, line := range lines {
+		drawText(img, face, light, margin, y, line)
+		y += lineHeight
+	}
+
+	small, err := newFace(regular, 32)
+	if err != nil {
+		return nil, err
+	}
+	if c.Date != "" {
+		drawText(img, small, muted, margin, y+16, c.Date)
+	}
+
+	chipFace, err := newFace(regular, 28)
+	if err != nil {
+		return nil, err
+	}
+	x, chipY := margin, Height-margin-48
+	for i, tag := range c.Tags {
+		if i == maxTags {
+			break
+		}
+		w := font.MeasureString(chipFace, tag).Ceil() + 40
+		if x+w > Width-margin {
+			break
+		}
+		draw.Draw(img, image.Rect(x, chipY, x+w, chipY+48), image.NewUniform(chip), image.Point{}, draw.Src)
+		drawText(img, chipFace, light, x+20, chipY+34, tag)
+		x += w + 16
+	}
+
+	if c.Site != "" {
+		siteFace, err := newFace(bold, 36)
+		if err != nil {
+			return nil, err
+		}
+		w := font.MeasureString(siteFace, c.Site).Ceil()
+		drawText(img, siteFace, primary, Width-margin-w, Height-margin-10, c.Site)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encoding png: %w", err)
+	}
+	return withVersion(buf.Bytes(), c.Version()), nil
+}
+
+const (
+	// pngHeaderSize is the size of the PNG signature and the IHDR chunk, which always comes first.
+	pngHeaderSize = 8 + 4 + 4 + 13 + 4
+	versionKey    = "Version"
+)
+
+// withVersion adds a tEXt chunk with the version to the PNG straight after its header.
+func withVersion(data []byte, version string) []byte {
+	text := []byte(versionKey + "\x00" + version)
+	chunk := make([]byte, 0, 12+len(text))
+	chunk = binary.BigEndian.AppendUint32(chunk, uint32(len(text)))
+	chunk = append(chunk, "tEXt"...)
+	chunk = append(chunk, text...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	out := make([]byte, 0, len(data)+len(chunk))
+	out = append(out, data[:pngHeaderSize]...)
+	out = append(out, chunk...)
+	return append(out, data[pngHeaderSize:]...)
+}
+
+// VersionOf returns the version of the card Render drew in the PNG, or "" if it doesn't have one.
+func VersionOf(data []byte) string {
+	for i := 8; i+8 <= len(data); {
+		size := int(binary.BigEndian.Uint32(data[i:]))
+		typ := string(data[i+4 : i+8])
+		if size < 0 || i+12+size > len(data) || typ == "IDAT" {
+			return ""
+		}
+		if typ == "tEXt" {
+			if key, val, ok := bytes.Cut(data[i+8:i+8+size], []byte{0}); ok && string(key) == versionKey {
+				return string(val)
+			}
+		}
+		i += 12 + size
+	}
+	return ""
+}
+
+func newFace(f *opentype.Font, size float64) (font.Face, error) {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("creating font face: %w", err)
+	}
+	return face, nil
+}
+
+func drawText(img draw.Image, face font.Face, c color.Color, x, y int, s string) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
+
+// wrap splits s into lines no wider than width, breaking between words.
+func wrap(face font.Face, s string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		// Words too wide for a line on their own, like URLs, are broken over lines.
+		for i, piece := range breakWord(face, word, width) {
+			next := piece
+			if line != "" {
+				next = line + " " + piece
+			}
+			if line != "" && (i > 0 || font.MeasureString(face, next).Ceil() > width) {
+				lines = append(lines, line)
+				next = piece
+			}
+			line = next
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// breakWord splits the word into pieces that each fit in width, it's left whole if it fits.
+func breakWord(face font.Face, word string, width int) []string {
+	if font.MeasureString(face, word).Ceil() <= width {
+		return []string{word}
+	}
+	var pieces []string
+	piece := ""
+	for _, r := range word {
+		if piece != "" && font.MeasureString(face, piece+string(r)).Ceil() > width {
+			pieces = append(pieces, piece)
+			piece = ""
+		}
+		piece += string(r)
+	}
+	return append(pieces, piece)
+}
+
+// ellipsis cuts s short with an ellipsis so it fits in width.
+func ellipsis(face font.Face, s string, width int) string {
+	runes := []rune(s)
+	for len(runes) > 0 && font.MeasureString(face, string(runes)+"…").Ceil() > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "…"
+}
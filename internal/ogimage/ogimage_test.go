@@ -0,0 +1,99 @@
+package ogimage
+
+import (
+	"bytes"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font"
+)
+
+func TestRender(t *testing.T) {
+	tests := map[string]Card{
+		"full":        {Title: "Deploying a Go website to AWS Elastic Beanstalk", Date: "3 November 2023", Site: "warrenb95", Tags: []string{"go", "aws"}},
+		"long title":  {Title: strings.Repeat("Really quite long title words ", 20), Tags: []string{"a", "b", "c", "d", "e", "f"}},
+		"empty":       {},
+		"long tag":    {Title: "Tags", Tags: []string{strings.Repeat("kubernetes", 10)}},
+		"unicode":     {Title: "Café crème & ünïcödé 🚀", Site: "warrenb95"},
+		"single word": {Title: strings.Repeat("a", 100)},
+	}
+
+	for name, card := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := Render(card)
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("not a png: %v", err)
+			}
+			if b := img.Bounds(); b.Dx() != Width || b.Dy() != Height {
+				t.Errorf("size = %dx%d, want %dx%d", b.Dx(), b.Dy(), Width, Height)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	face, err := newFace(bold, 72)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := wrap(face, "Deploying a Go website to AWS Elastic Beanstalk with DynamoDB and S3", Width-2*margin)
+	if len(lines) < 2 {
+		t.Fatalf("lines = %q, want it wrapped", lines)
+	}
+	if got := strings.Join(lines, " "); got != "Deploying a Go website to AWS Elastic Beanstalk with DynamoDB and S3" {
+		t.Errorf("wrapping lost words: %q", got)
+	}
+	for _, line := range lines {
+		if w := font.MeasureString(face, line).Ceil(); w > Width-2*margin {
+			t.Errorf("%q is %dpx wide", line, w)
+		}
+	}
+
+	long := "See https://example.com/" + strings.Repeat("a-very-long-path/", 10)
+	lines = wrap(face, long, Width-2*margin)
+	if len(lines) < 2 {
+		t.Fatalf("lines = %q, want the long word broken", lines)
+	}
+	if got := strings.ReplaceAll(strings.Join(lines, ""), " ", ""); got != strings.ReplaceAll(long, " ", "") {
+		t.Errorf("breaking lost letters: %q", got)
+	}
+	for _, line := range lines {
+		if w := font.MeasureString(face, line).Ceil(); w > Width-2*margin {
+			t.Errorf("%q is %dpx wide", line, w)
+		}
+	}
+
+	if got := wrap(face, "  ", 100); !reflect.DeepEqual(got, []string(nil)) {
+		t.Errorf("blank title wrapped to %q", got)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	card := Card{Title: "htmx & Go", Tags: []string{"go"}}
+	data, err := Render(card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("not a png with the version in it: %v", err)
+	}
+	if got := VersionOf(data); got != card.Version() {
+		t.Errorf("version = %q, want %q", got, card.Version())
+	}
+
+	edited := card
+	edited.Title = "htmx and Go"
+	if edited.Version() == card.Version() {
+		t.Error("version didn't change with the title")
+	}
+	if got := VersionOf([]byte("not a png")); got != "" {
+		t.Errorf("version of garbage = %q", got)
+	}
+}
@@ -0,0 +1,254 @@
+// Package sanitize strips rendered HTML down to an allowlist of elements, attributes and URL schemes,
+// so raw HTML in markdown can't run script on the site.
+package sanitize
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Policy is what a Sanitizer lets through.
+type Policy struct {
+	// Elements are the elements kept. Others are replaced by their content, or removed with it for
+	// elements like <script> whose content isn't text to show, which are never kept.
+	Elements []string
+	// Attributes are the attributes kept on every element like "class", or on one element like "a.href".
+	// Event handlers like onclick are never kept.
+	Attributes []string
+	// Schemes are the URL schemes links and images can use, relative URLs are always allowed.
+	Schemes []string
+	// IframeHosts are the hosts <iframe>s can embed over https, e.g. "www.youtube-nocookie.com".
+	// Every iframe is removed without any.
+	IframeHosts []string
+}
+
+// DefaultPolicy lets through what the markdown renderer and the code highlighter make.
+func DefaultPolicy() Policy {
+	return Policy{
+		Elements: []string{
+			"p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote", "div", "span",
+			"strong", "em", "b", "i", "u", "s", "del", "ins", "sub", "sup", "mark", "small", "abbr", "q", "cite",
+			"code", "pre", "kbd", "samp", "var",
+			"ul", "ol", "li", "dl", "dt", "dd",
+			"a", "img", "figure", "figcaption", "details", "summary",
+			"table", "caption", "thead", "tbody", "tfoot", "tr", "th", "td",
+		},
+		Attributes: []string{
+			"class", "id", "title", "lang",
+			"a.href",
+			"img.src", "img.alt", "img.width", "img.height",
+			"blockquote.cite", "q.cite",
+			"ol.start", "details.open",
+			"th.align", "td.align", "th.colspan", "td.colspan", "th.rowspan", "td.rowspan",
+		},
+		Schemes: []string{"http", "https", "mailto"},
+	}
+}
+
+// dropped are the elements removed with their content when they're not allowed.
+var dropped = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Frame:    true,
+	atom.Frameset: true,
+	atom.Noscript: true,
+	atom.Noembed:  true,
+	atom.Template: true,
+	atom.Textarea: true,
+	atom.Title:    true,
+	atom.Xmp:      true,
+	atom.Svg:      true,
+	atom.Math:     true,
+}
+
+// urlAttributes are the attributes holding URLs, checked against the allowed schemes.
+var urlAttributes = map[string]bool{
+	"href":   true,
+	"src":    true,
+	"cite":   true,
+	"action": true,
+	"poster": true,
+}
+
+// iframeAttributes are the attributes kept on iframes from the allowed hosts.
+var iframeAttributes = map[string]bool{
+	"src":             true,
+	"title":           true,
+	"width":           true,
+	"height":          true,
+	"allow":           true,
+	"allowfullscreen": true,
+	"loading":         true,
+	"referrerpolicy":  true,
+	"frameborder":     true,
+}
+
+// Sanitizer removes what its policy doesn't allow from HTML trees.
+type Sanitizer struct {
+	elements    map[string]bool
+	global      map[string]bool
+	attributes  map[string]map[string]bool
+	schemes     map[string]bool
+	iframeHosts map[string]bool
+}
+
+// New returns a sanitizer for the policy. Names are matched case insensitively.
+func New(p Policy) *Sanitizer {
+	s := &Sanitizer{
+		elements:    set(p.Elements),
+		global:      make(map[string]bool),
+		attributes:  make(map[string]map[string]bool),
+		schemes:     set(p.Schemes),
+		iframeHosts: set(p.IframeHosts),
+	}
+	// Elements like <script> are never kept, and iframes only from the allowed hosts.
+	for element := range s.elements {
+		if dropped[atom.Lookup([]byte(element))] {
+			delete(s.elements, element)
+		}
+	}
+
+	for _, a := range p.Attributes {
+		a = strings.ToLower(strings.TrimSpace(a))
+		element, name, ok := strings.Cut(a, ".")
+		if !ok {
+			s.global[a] = true
+			continue
+		}
+		if s.attributes[element] == nil {
+			s.attributes[element] = make(map[string]bool)
+		}
+		s.attributes[element][name] = true
+	}
+
+	return s
+}
+
+// Sanitize removes what isn't allowed from under n, n itself is left alone.
+func (s *Sanitizer) Sanitize(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+
+		switch c.Type {
+		case html.TextNode:
+		case html.ElementNode:
+			switch {
+			case c.DataAtom == atom.Iframe:
+				if !s.allowIframe(c) {
+					n.RemoveChild(c)
+				}
+			case c.Namespace == "" && s.elements[c.Data]:
+				c.Attr = s.attributesOf(c)
+				s.Sanitize(c)
+			case dropped[c.DataAtom] || c.Namespace != "":
+				n.RemoveChild(c)
+			default:
+				// Keep the content of the element, sanitized, in its place.
+				s.Sanitize(c)
+				for gc := c.FirstChild; gc != nil; gc = c.FirstChild {
+					c.RemoveChild(gc)
+					n.InsertBefore(gc, c)
+				}
+				n.RemoveChild(c)
+			}
+		default:
+			// Comments and doctypes.
+			n.RemoveChild(c)
+		}
+
+		c = next
+	}
+}
+
+// attributesOf returns the element's allowed attributes.
+func (s *Sanitizer) attributesOf(n *html.Node) []html.Attribute {
+	var kept []html.Attribute
+	for _, a := range n.Attr {
+		key := strings.ToLower(a.Key)
+		if a.Namespace != "" || strings.HasPrefix(key, "on") {
+			continue
+		}
+		if !s.global[key] && !s.attributes[n.Data][key] {
+			continue
+		}
+		if urlAttributes[key] {
+			u, ok := s.url(a.Val)
+			if !ok {
+				continue
+			}
+			a.Val = u
+		}
+		kept = append(kept, a)
+	}
+	return kept
+}
+
+// allowIframe reports whether the iframe embeds an allowed host over https, removing the attributes
+// iframes don't need and everything inside it.
+func (s *Sanitizer) allowIframe(n *html.Node) bool {
+	if len(s.iframeHosts) == 0 {
+		return false
+	}
+
+	var kept []html.Attribute
+	allowed := false
+	for _, a := range n.Attr {
+		key := strings.ToLower(a.Key)
+		if a.Namespace != "" || !iframeAttributes[key] {
+			continue
+		}
+		if key == "src" {
+			v, ok := s.url(a.Val)
+			if !ok {
+				return false
+			}
+			u, err := url.Parse(v)
+			if err != nil || u.Scheme != "https" || !s.iframeHosts[strings.ToLower(u.Hostname())] {
+				return false
+			}
+			a.Val, allowed = v, true
+		}
+		kept = append(kept, a)
+	}
+	if !allowed {
+		return false
+	}
+
+	n.Attr = kept
+	for c := n.FirstChild; c != nil; c = n.FirstChild {
+		n.RemoveChild(c)
+	}
+	return true
+}
+
+// url returns the URL trimmed the way browsers trim it, and whether it's relative or has an allowed scheme.
+func (s *Sanitizer) url(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	// Browsers ignore tabs and newlines anywhere in a URL, so "java\tscript:" is javascript:.
+	if strings.IndexFunc(raw, func(r rune) bool { return r < 0x20 || r == 0x7f }) >= 0 {
+		return "", false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	if u.Scheme != "" && !s.schemes[u.Scheme] {
+		return "", false
+	}
+	return raw, true
+}
+
+// set returns the names lower cased as a set.
+func set(names []string) map[string]bool {
+	m := make(map[string]bool, len(names))
+	for _, name := range names {
+		m[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	return m
+}
@@ -0,0 +1,95 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func TestSanitize(t *testing.T) {
+	trusted := DefaultPolicy()
+	trusted.IframeHosts = []string{"www.youtube-nocookie.com"}
+
+	tests := map[string]struct {
+		policy Policy
+		in     string
+		want   string
+	}{
+		"allowed": {
+			in:   `<h2 id="setup" class="x">Set <em>up</em></h2><p><a href="/about" title="me">me</a> <img src="https://example.com/a.png" alt="a"/></p>`,
+			want: `<h2 id="setup" class="x">Set <em>up</em></h2><p><a href="/about" title="me">me</a> <img src="https://example.com/a.png" alt="a"/></p>`,
+		},
+		"highlighted code": {
+			in:   `<figure class="code-block"><figcaption class="code-title">main.go</figcaption><div class="chroma"><table class="lntable"><tbody><tr><td class="lntd"><pre class="chroma"><code><span class="line hl"><span class="kn">package</span></span></code></pre></td></tr></tbody></table></div></figure>`,
+			want: `<figure class="code-block"><figcaption class="code-title">main.go</figcaption><div class="chroma"><table class="lntable"><tbody><tr><td class="lntd"><pre class="chroma"><code><span class="line hl"><span class="kn">package</span></span></code></pre></td></tr></tbody></table></div></figure>`,
+		},
+		"scripts": {
+			in:   `<p>hi<script>alert(1)</script></p><style>p{}</style><svg><script>alert(1)</script></svg><noscript><img src=x></noscript>`,
+			want: `<p>hi</p>`,
+		},
+		"event handlers": {
+			in:   `<p onclick="alert(1)" ONMOUSEOVER="alert(1)">hi</p><img src="a.png" onerror="alert(1)"/>`,
+			want: `<p>hi</p><img src="a.png"/>`,
+		},
+		"unknown attributes": {
+			in:   `<p style="position:fixed" align="center">hi</p><a href="/" target="_top">a</a>`,
+			want: `<p>hi</p><a href="/">a</a>`,
+		},
+		"unknown elements": {
+			in:   `<form action="/x"><button>Click <b>me</b></button><input name="q"/></form><!-- a comment -->`,
+			want: `Click <b>me</b>`,
+		},
+		"urls": {
+			in: `<a href="javascript:alert(1)">a</a><a href=" JavaScript:alert(1)">b</a><a href="java&#09;script:alert(1)">c</a>` +
+				`<a href="&#106;avascript:alert(1)">d</a><img src="data:image/png;base64,AAAA"/><a href="mailto:me@example.com">e</a><a href="#top">f</a>`,
+			want: `<a>a</a><a>b</a><a>c</a><a>d</a><img/><a href="mailto:me@example.com">e</a><a href="#top">f</a>`,
+		},
+		"untrusted iframes": {
+			in:   `<p>watch</p><iframe src="https://www.youtube-nocookie.com/embed/x"></iframe>`,
+			want: `<p>watch</p>`,
+		},
+		"trusted iframes": {
+			policy: trusted,
+			in: `<iframe src="https://www.youtube-nocookie.com/embed/x" width="560" allowfullscreen onload="alert(1)" srcdoc="&lt;script&gt;"></iframe>` +
+				`<iframe src="http://www.youtube-nocookie.com/embed/x"></iframe><iframe src="https://evil.example.com/"></iframe><iframe></iframe>`,
+			want: `<iframe src="https://www.youtube-nocookie.com/embed/x" width="560" allowfullscreen=""></iframe>`,
+		},
+		"configured": {
+			policy: Policy{Elements: []string{"p", "a", "script"}, Attributes: []string{"A.HREF"}, Schemes: []string{"ftp"}},
+			in:     `<p class="x"><a href="ftp://example.com/f" title="f">f</a> <a href="https://example.com">g</a> <em>h</em></p><script>alert(1)</script>`,
+			want:   `<p><a href="ftp://example.com/f">f</a> <a>g</a> h</p>`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := tc.policy
+			if policy.Elements == nil {
+				policy = DefaultPolicy()
+			}
+
+			body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+			nodes, err := html.ParseFragment(strings.NewReader(tc.in), body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, n := range nodes {
+				body.AppendChild(n)
+			}
+
+			New(policy).Sanitize(body)
+
+			var sb strings.Builder
+			for n := body.FirstChild; n != nil; n = n.NextSibling {
+				if err := html.Render(&sb, n); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := sb.String(); got != tc.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
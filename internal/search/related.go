@@ -0,0 +1,103 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// How much sharing tags and sharing words count towards documents being related, adding up to 1.
+const (
+	relatedTagWeight  = 0.4
+	relatedTermWeight = 0.6
+)
+
+// Related returns the slugs of each document's most related documents, up to n of them, best first.
+// Documents are related by the tags they share and by the cosine similarity of their TF-IDF term
+// vectors. Documents with nothing in common aren't related, so some may have fewer than n or none.
+func (idx *Index) Related(n int) map[string][]string {
+	count := len(idx.docs)
+	scores := make([][]float64, count)
+	for i := range scores {
+		scores[i] = make([]float64, count)
+	}
+
+	// Dot products of the term vectors, visiting only the documents sharing each term.
+	// The terms are summed in order so ties are broken the same way every time.
+	norms := make([]float64, count)
+	for _, t := range idx.terms {
+		ps := idx.postings[t]
+		idf := math.Log(float64(count) / float64(len(ps)))
+		if idf == 0 {
+			// Terms in every document don't tell them apart.
+			continue
+		}
+		for a, pa := range ps {
+			wa := pa.tf * idf
+			norms[pa.doc] += wa * wa
+			for _, pb := range ps[a+1:] {
+				dot := wa * pb.tf * idf
+				scores[pa.doc][pb.doc] += dot
+				scores[pb.doc][pa.doc] += dot
+			}
+		}
+	}
+	for i := range scores {
+		for j := range scores[i] {
+			if scores[i][j] > 0 {
+				scores[i][j] *= relatedTermWeight / math.Sqrt(norms[i]*norms[j])
+			}
+		}
+	}
+
+	tags := make([]map[string]bool, count)
+	for i, d := range idx.docs {
+		tags[i] = make(map[string]bool, len(d.Tags))
+		for _, t := range d.Tags {
+			tags[i][strings.ToLower(t)] = true
+		}
+	}
+	for i := range tags {
+		for j := i + 1; j < count; j++ {
+			if s := jaccard(tags[i], tags[j]); s > 0 {
+				scores[i][j] += relatedTagWeight * s
+				scores[j][i] += relatedTagWeight * s
+			}
+		}
+	}
+
+	related := make(map[string][]string, count)
+	for i, d := range idx.docs {
+		var others []int
+		for j, s := range scores[i] {
+			if j != i && s > 0 {
+				others = append(others, j)
+			}
+		}
+		sort.SliceStable(others, func(a, b int) bool {
+			return scores[i][others[a]] > scores[i][others[b]]
+		})
+		if len(others) > n {
+			others = others[:n]
+		}
+		for _, j := range others {
+			related[d.Slug] = append(related[d.Slug], idx.docs[j].Slug)
+		}
+	}
+
+	return related
+}
+
+// jaccard is how many of the tags in either set are in both.
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
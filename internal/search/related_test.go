@@ -0,0 +1,43 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRelated(t *testing.T) {
+	idx := New([]Document{
+		{Slug: "htmx_cards", Title: "Loading cards with htmx", Tags: []string{"htmx"}, Body: "Cards are swapped in with hx-get as you scroll."},
+		{Slug: "htmx_search", Title: "Searching with htmx", Tags: []string{"HTMX", "search"}, Body: "Results are swapped in with hx-get as you type."},
+		{Slug: "aws", Title: "Deploying to AWS", Tags: []string{"aws"}, Body: "Zip the binary and upload it to Elastic Beanstalk."},
+		{Slug: "beanstalk", Title: "Elastic Beanstalk logs", Body: "The logs are zipped and uploaded to S3."},
+	})
+
+	want := map[string][]string{
+		"htmx_cards":  {"htmx_search"},
+		"htmx_search": {"htmx_cards"},
+		"aws":         {"beanstalk"},
+		"beanstalk":   {"aws"},
+	}
+	if got := idx.Related(3); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got := idx.Related(0); len(got) != 0 {
+		t.Errorf("got %v with n of 0", got)
+	}
+}
+
+func TestRelatedOrder(t *testing.T) {
+	idx := New([]Document{
+		{Slug: "go", Tags: []string{"go", "htmx"}, Body: "Templates in Go."},
+		{Slug: "unrelated", Body: "Something else entirely."},
+		{Slug: "tag", Tags: []string{"go"}, Body: "Nothing in common."},
+		{Slug: "both", Tags: []string{"go", "htmx"}, Body: "Templates in Go."},
+	})
+
+	got := idx.Related(2)["go"]
+	if want := []string{"both", "tag"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
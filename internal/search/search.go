@@ -0,0 +1,270 @@
+// Package search is an in-memory full-text index of the blogs, ranked with BM25.
+package search
+
+import (
+	"html/template"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Document is a blog as it's indexed. Body is plain text, not markdown or HTML.
+type Document struct {
+	Slug    string
+	Title   string
+	Summary string
+	Tags    []string
+	Body    string
+}
+
+// Terms in titles and tags count for more than those in the body.
+const (
+	titleWeight   = 3
+	tagWeight     = 3
+	summaryWeight = 2
+	bodyWeight    = 1
+)
+
+// BM25's term frequency saturation and length normalisation, see https://en.wikipedia.org/wiki/Okapi_BM25.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// prefixWeight is how much a word the last word of a query is the start of counts for.
+const prefixWeight = 0.5
+
+// snippetWords is how many words are in a snippet, starting snippetLead words before the first match.
+const (
+	snippetWords = 30
+	snippetLead  = 5
+)
+
+// Index is an inverted index of documents. It isn't changed once built, so it's safe to search concurrently.
+type Index struct {
+	docs     []Document
+	postings map[string][]posting
+	// terms are the indexed terms sorted, to find the ones starting with a prefix.
+	terms   []string
+	lengths []float64
+	avgLen  float64
+}
+
+// posting is how often a term is in a document, weighted by the field it's in.
+type posting struct {
+	doc int
+	tf  float64
+}
+
+// New indexes the documents. Results with the same score are in the order the documents are.
+func New(docs []Document) *Index {
+	idx := &Index{
+		docs:     docs,
+		postings: make(map[string][]posting),
+		lengths:  make([]float64, len(docs)),
+	}
+
+	var total float64
+	for i, d := range docs {
+		tf := make(map[string]float64)
+		add := func(text string, weight float64) {
+			for _, t := range Terms(text) {
+				tf[t] += weight
+				idx.lengths[i] += weight
+			}
+		}
+		add(d.Title, titleWeight)
+		add(strings.Join(d.Tags, " "), tagWeight)
+		add(d.Summary, summaryWeight)
+		add(d.Body, bodyWeight)
+
+		for t, f := range tf {
+			idx.postings[t] = append(idx.postings[t], posting{doc: i, tf: f})
+		}
+		total += idx.lengths[i]
+	}
+	if len(docs) > 0 {
+		idx.avgLen = total / float64(len(docs))
+	}
+
+	idx.terms = make([]string, 0, len(idx.postings))
+	for t := range idx.postings {
+		idx.terms = append(idx.terms, t)
+	}
+	sort.Strings(idx.terms)
+
+	return idx
+}
+
+// Query is what to search for.
+type Query struct {
+	Text string
+	// Prefix also matches words the last word of Text is the start of, for searching as it's typed.
+	Prefix bool
+	// Limit is the most results returned, all of them when it's 0.
+	Limit int
+}
+
+// Result is a matching document with the matched words highlighted in <mark>s.
+type Result struct {
+	Slug  string
+	Title template.HTML
+	// Snippet is the part of the body with the most matches, or the summary.
+	Snippet template.HTML
+	Score   float64
+}
+
+// Search returns the documents matching any of the query's terms, best first, and how many matched.
+func (idx *Index) Search(q Query) ([]Result, int) {
+	weights := idx.queryTerms(q)
+	if len(weights) == 0 || len(idx.docs) == 0 {
+		return nil, 0
+	}
+
+	scores := make(map[int]float64)
+	n := float64(len(idx.docs))
+	for t, w := range weights {
+		ps := idx.postings[t]
+		idf := math.Log(1 + (n-float64(len(ps))+0.5)/(float64(len(ps))+0.5))
+		for _, p := range ps {
+			norm := k1 * (1 - b + b*idx.lengths[p.doc]/idx.avgLen)
+			scores[p.doc] += w * idf * p.tf * (k1 + 1) / (p.tf + norm)
+		}
+	}
+
+	ranked := make([]int, 0, len(scores))
+	for doc := range scores {
+		ranked = append(ranked, doc)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		si, sj := scores[ranked[i]], scores[ranked[j]]
+		if si != sj {
+			return si > sj
+		}
+		return ranked[i] < ranked[j]
+	})
+
+	total := len(ranked)
+	if q.Limit > 0 && len(ranked) > q.Limit {
+		ranked = ranked[:q.Limit]
+	}
+
+	results := make([]Result, len(ranked))
+	for i, doc := range ranked {
+		d := idx.docs[doc]
+		results[i] = Result{
+			Slug:    d.Slug,
+			Title:   highlight(d.Title, weights),
+			Snippet: snippet(d, weights),
+			Score:   scores[doc],
+		}
+	}
+
+	return results, total
+}
+
+// queryTerms returns the indexed terms the query matches and how much each counts for.
+func (idx *Index) queryTerms(q Query) map[string]float64 {
+	weights := make(map[string]float64)
+	for _, t := range Terms(q.Text) {
+		if _, ok := idx.postings[t]; ok {
+			weights[t] = 1
+		}
+	}
+
+	ws := words(q.Text)
+	if !q.Prefix || len(ws) == 0 || ws[len(ws)-1].end != len(q.Text) {
+		// The last word is finished when it's followed by a space.
+		return weights
+	}
+	prefix := strings.ToLower(q.Text[ws[len(ws)-1].start:])
+	if len(prefix) < 2 {
+		return weights
+	}
+	for i := sort.SearchStrings(idx.terms, prefix); i < len(idx.terms) && strings.HasPrefix(idx.terms[i], prefix); i++ {
+		if _, ok := weights[idx.terms[i]]; !ok {
+			weights[idx.terms[i]] = prefixWeight
+		}
+	}
+
+	return weights
+}
+
+// snippet returns the part of the body with the most matching words, or the summary when none of the body matches.
+func snippet(d Document, matches map[string]float64) template.HTML {
+	ws := words(d.Body)
+	matched := func(i int) bool {
+		_, ok := matches[ws[i].term]
+		return ok
+	}
+
+	// Find the window with the most matches, then start it a few words before its first one.
+	first, most, count := 0, 0, 0
+	for i := range ws {
+		if matched(i) {
+			count++
+		}
+		if i >= snippetWords && matched(i-snippetWords) {
+			count--
+		}
+		if count > most {
+			most = count
+			first = i - snippetWords + 1
+			if first < 0 {
+				first = 0
+			}
+			for !matched(first) {
+				first++
+			}
+		}
+	}
+	if most == 0 && d.Summary != "" {
+		return highlight(d.Summary, matches)
+	}
+	if len(ws) == 0 {
+		return ""
+	}
+
+	start := first - snippetLead
+	if start < 0 {
+		start = 0
+	}
+	end := start + snippetWords
+	if end > len(ws) {
+		end = len(ws)
+	}
+
+	// Keep the punctuation at the start and end of the body.
+	from, to := 0, len(d.Body)
+	var sb strings.Builder
+	if start > 0 {
+		from = ws[start].start
+		sb.WriteString("… ")
+	}
+	if end < len(ws) {
+		to = ws[end-1].end
+	}
+	sb.WriteString(string(highlight(d.Body[from:to], matches)))
+	if end < len(ws) {
+		sb.WriteString(" …")
+	}
+	return template.HTML(sb.String())
+}
+
+// highlight escapes text, wrapping the words matching a term in <mark>s.
+func highlight(text string, matches map[string]float64) template.HTML {
+	var sb strings.Builder
+	last := 0
+	for _, w := range words(text) {
+		if _, ok := matches[w.term]; !ok || w.term == "" {
+			continue
+		}
+		sb.WriteString(template.HTMLEscapeString(text[last:w.start]))
+		sb.WriteString("<mark>")
+		sb.WriteString(template.HTMLEscapeString(text[w.start:w.end]))
+		sb.WriteString("</mark>")
+		last = w.end
+	}
+	sb.WriteString(template.HTMLEscapeString(text[last:]))
+	return template.HTML(sb.String())
+}
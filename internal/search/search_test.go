@@ -0,0 +1,111 @@
+package search
+
+import (
+	"html/template"
+	"reflect"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	got := Terms("The Deploying of Go apps, to AWS in 2 steps!")
+	want := []string{"deploi", "go", "app", "aw", "2", "step"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	idx := New([]Document{
+		{Slug: "htmx", Title: "Infinite scroll with htmx", Summary: "Loading more cards.", Tags: []string{"htmx"}, Body: "Cards are loaded as you scroll."},
+		{Slug: "deploy", Title: "Deploying to AWS", Summary: "Elastic Beanstalk.", Tags: []string{"aws"}, Body: "Deployments are zipped & uploaded. Deploying Go is easy."},
+		{Slug: "go", Title: "Hello Go", Summary: "The first blog.", Tags: []string{"go"}, Body: "Mentions deploying once."},
+	})
+
+	tests := map[string]struct {
+		query     Query
+		wantSlugs []string
+		wantTotal int
+	}{
+		"stemmed": {
+			query:     Query{Text: "deployed"},
+			wantSlugs: []string{"deploy", "go"},
+			wantTotal: 2,
+		},
+		"title and tags rank higher": {
+			query:     Query{Text: "go"},
+			wantSlugs: []string{"go", "deploy"},
+			wantTotal: 2,
+		},
+		"any term": {
+			query:     Query{Text: "htmx elastic"},
+			wantSlugs: []string{"htmx", "deploy"},
+			wantTotal: 2,
+		},
+		"limit": {
+			query:     Query{Text: "deploy", Limit: 1},
+			wantSlugs: []string{"deploy"},
+			wantTotal: 2,
+		},
+		"prefix": {
+			query:     Query{Text: "infin", Prefix: true},
+			wantSlugs: []string{"htmx"},
+			wantTotal: 1,
+		},
+		"prefix only for the last word": {
+			query:     Query{Text: "infin ", Prefix: true},
+			wantTotal: 0,
+		},
+		"no prefix": {
+			query:     Query{Text: "infin"},
+			wantTotal: 0,
+		},
+		"stop words": {
+			query:     Query{Text: "the"},
+			wantTotal: 0,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			results, total := idx.Search(tc.query)
+			var slugs []string
+			for _, r := range results {
+				slugs = append(slugs, r.Slug)
+			}
+			if !reflect.DeepEqual(slugs, tc.wantSlugs) {
+				t.Errorf("got %q, want %q", slugs, tc.wantSlugs)
+			}
+			if total != tc.wantTotal {
+				t.Errorf("total = %d, want %d", total, tc.wantTotal)
+			}
+		})
+	}
+}
+
+func TestSearchHighlights(t *testing.T) {
+	long := "Some words before the match go on and on for a while, well over thirty words, so the snippet " +
+		"has to skip them to get to the part about templates. Go <templates> are used for every page here. " +
+		"Then a long tail of words follows the match, on and on, for more than enough words to be cut off at the end."
+	idx := New([]Document{
+		{Slug: "templates", Title: "Go templates & htmx", Body: long},
+		{Slug: "summary", Title: "Another", Summary: "All about templates.", Body: "Nothing matches here."},
+	})
+
+	results, _ := idx.Search(Query{Text: "template"})
+	bySlug := make(map[string]Result)
+	for _, r := range results {
+		bySlug[r.Slug] = r
+	}
+
+	got := bySlug["templates"]
+	if want := template.HTML("Go <mark>templates</mark> &amp; htmx"); got.Title != want {
+		t.Errorf("title = %q, want %q", got.Title, want)
+	}
+	wantSnippet := template.HTML("… get to the part about <mark>templates</mark>. Go &lt;<mark>templates</mark>&gt; are used for every page here. Then a long tail of words follows the match, on and on, for more than enough …")
+	if got.Snippet != wantSnippet {
+		t.Errorf("snippet = %q, want %q", got.Snippet, wantSnippet)
+	}
+	if want := template.HTML("All about <mark>templates</mark>."); bySlug["summary"].Snippet != want {
+		t.Errorf("summary snippet = %q, want %q", bySlug["summary"].Snippet, want)
+	}
+}
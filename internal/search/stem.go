@@ -0,0 +1,294 @@
+package search
+
+// stem returns the Porter stem of a lower case word, so "connected", "connecting" and "connection"
+// are all indexed as "connect". Words that aren't all ASCII letters are returned unchanged.
+// It follows the reference implementation at https://tartarus.org/martin/PorterStemmer/.
+func stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	z := &stemmer{b: []byte(word), k: len(word) - 1}
+	z.step1ab()
+	if z.k > 0 {
+		z.step1c()
+		z.step2()
+		z.step3()
+		z.step4()
+		z.step5()
+	}
+
+	return string(z.b[:z.k+1])
+}
+
+// stemmer is a word being stemmed, b[:k+1] is the stem so far and j is where a matched suffix starts.
+type stemmer struct {
+	b    []byte
+	k, j int
+}
+
+// cons reports whether b[i] is a consonant.
+func (z *stemmer) cons(i int) bool {
+	switch z.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !z.cons(i-1)
+	}
+	return true
+}
+
+// m counts the vowel-consonant sequences in b[:j+1].
+func (z *stemmer) m() int {
+	n, i := 0, 0
+	for {
+		if i > z.j {
+			return n
+		}
+		if !z.cons(i) {
+			break
+		}
+		i++
+	}
+	i++
+	for {
+		for {
+			if i > z.j {
+				return n
+			}
+			if z.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+		n++
+		for {
+			if i > z.j {
+				return n
+			}
+			if !z.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+	}
+}
+
+// vowelInStem reports whether b[:j+1] has a vowel.
+func (z *stemmer) vowelInStem() bool {
+	for i := 0; i <= z.j; i++ {
+		if !z.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doublec reports whether b[i-1:i+1] is a double consonant.
+func (z *stemmer) doublec(i int) bool {
+	return i >= 1 && z.b[i] == z.b[i-1] && z.cons(i)
+}
+
+// cvc reports whether b[i-2:i+1] is consonant-vowel-consonant and the last isn't w, x or y,
+// like "hop" but not "snow".
+func (z *stemmer) cvc(i int) bool {
+	if i < 2 || !z.cons(i) || z.cons(i-1) || !z.cons(i-2) {
+		return false
+	}
+	switch z.b[i] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+// ends reports whether the stem ends with s, setting j to before it.
+func (z *stemmer) ends(s string) bool {
+	if len(s) > z.k+1 || string(z.b[z.k-len(s)+1:z.k+1]) != s {
+		return false
+	}
+	z.j = z.k - len(s)
+	return true
+}
+
+// setTo replaces the suffix after j with s.
+func (z *stemmer) setTo(s string) {
+	z.b = append(z.b[:z.j+1], s...)
+	z.k = z.j + len(s)
+}
+
+// replace replaces the suffix after j with s when there's a vowel-consonant sequence before it.
+func (z *stemmer) replace(s string) {
+	if z.m() > 0 {
+		z.setTo(s)
+	}
+}
+
+// step1ab removes plurals and -ed or -ing.
+func (z *stemmer) step1ab() {
+	if z.b[z.k] == 's' {
+		switch {
+		case z.ends("sses"):
+			z.k -= 2
+		case z.ends("ies"):
+			z.setTo("i")
+		case z.b[z.k-1] != 's':
+			z.k--
+		}
+	}
+
+	if z.ends("eed") {
+		if z.m() > 0 {
+			z.k--
+		}
+		return
+	}
+	if !(z.ends("ed") || z.ends("ing")) || !z.vowelInStem() {
+		return
+	}
+	z.k = z.j
+	switch {
+	case z.ends("at"):
+		z.setTo("ate")
+	case z.ends("bl"):
+		z.setTo("ble")
+	case z.ends("iz"):
+		z.setTo("ize")
+	case z.doublec(z.k):
+		z.k--
+		switch z.b[z.k] {
+		case 'l', 's', 'z':
+			z.k++
+		}
+	case z.m() == 1 && z.cvc(z.k):
+		z.setTo("e")
+	}
+}
+
+// step1c turns a final y into i when there's another vowel in the stem.
+func (z *stemmer) step1c() {
+	if z.ends("y") && z.vowelInStem() {
+		z.b[z.k] = 'i'
+	}
+}
+
+// suffix is a suffix and what it's replaced with.
+type suffix struct {
+	from, to string
+}
+
+// replaceFirst replaces the first of the suffixes the stem ends with.
+func (z *stemmer) replaceFirst(suffixes ...suffix) {
+	for _, s := range suffixes {
+		if z.ends(s.from) {
+			z.replace(s.to)
+			return
+		}
+	}
+}
+
+// step2 maps double suffixes to single ones, like -ization to -ize.
+func (z *stemmer) step2() {
+	switch z.b[z.k-1] {
+	case 'a':
+		z.replaceFirst(suffix{"ational", "ate"}, suffix{"tional", "tion"})
+	case 'c':
+		z.replaceFirst(suffix{"enci", "ence"}, suffix{"anci", "ance"})
+	case 'e':
+		z.replaceFirst(suffix{"izer", "ize"})
+	case 'l':
+		z.replaceFirst(suffix{"bli", "ble"}, suffix{"alli", "al"}, suffix{"entli", "ent"}, suffix{"eli", "e"}, suffix{"ousli", "ous"})
+	case 'o':
+		z.replaceFirst(suffix{"ization", "ize"}, suffix{"ation", "ate"}, suffix{"ator", "ate"})
+	case 's':
+		z.replaceFirst(suffix{"alism", "al"}, suffix{"iveness", "ive"}, suffix{"fulness", "ful"}, suffix{"ousness", "ous"})
+	case 't':
+		z.replaceFirst(suffix{"aliti", "al"}, suffix{"iviti", "ive"}, suffix{"biliti", "ble"})
+	case 'g':
+		z.replaceFirst(suffix{"logi", "log"})
+	}
+}
+
+// step3 handles -ic-, -full, -ness and the like.
+func (z *stemmer) step3() {
+	switch z.b[z.k] {
+	case 'e':
+		z.replaceFirst(suffix{"icate", "ic"}, suffix{"ative", ""}, suffix{"alize", "al"})
+	case 'i':
+		z.replaceFirst(suffix{"iciti", "ic"})
+	case 'l':
+		z.replaceFirst(suffix{"ical", "ic"}, suffix{"ful", ""})
+	case 's':
+		z.replaceFirst(suffix{"ness", ""})
+	}
+}
+
+// step4 removes -ant, -ence and the like when there are two vowel-consonant sequences before them.
+func (z *stemmer) step4() {
+	if z.k < 1 {
+		return
+	}
+	var suffixes []string
+	switch z.b[z.k-1] {
+	case 'a':
+		suffixes = []string{"al"}
+	case 'c':
+		suffixes = []string{"ance", "ence"}
+	case 'e':
+		suffixes = []string{"er"}
+	case 'i':
+		suffixes = []string{"ic"}
+	case 'l':
+		suffixes = []string{"able", "ible"}
+	case 'n':
+		suffixes = []string{"ant", "ement", "ment", "ent"}
+	case 'o':
+		if z.ends("ion") && z.j >= 0 && (z.b[z.j] == 's' || z.b[z.j] == 't') {
+			break
+		}
+		suffixes = []string{"ou"}
+	case 's':
+		suffixes = []string{"ism"}
+	case 't':
+		suffixes = []string{"ate", "iti"}
+	case 'u':
+		suffixes = []string{"ous"}
+	case 'v':
+		suffixes = []string{"ive"}
+	case 'z':
+		suffixes = []string{"ize"}
+	default:
+		return
+	}
+
+	matched := suffixes == nil
+	for _, s := range suffixes {
+		if z.ends(s) {
+			matched = true
+			break
+		}
+	}
+	if matched && z.m() > 1 {
+		z.k = z.j
+	}
+}
+
+// step5 removes a final -e and turns -ll into -l when there are enough vowel-consonant sequences.
+func (z *stemmer) step5() {
+	z.j = z.k
+	if z.b[z.k] == 'e' {
+		if a := z.m(); a > 1 || a == 1 && !z.cvc(z.k-1) {
+			z.k--
+		}
+	}
+	if z.b[z.k] == 'l' && z.doublec(z.k) && z.m() > 1 {
+		z.k--
+	}
+}
@@ -0,0 +1,38 @@
+package search
+
+import "testing"
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"cats":           "cat",
+		"feed":           "feed",
+		"agreed":         "agre",
+		"plastered":      "plaster",
+		"motoring":       "motor",
+		"sing":           "sing",
+		"conflated":      "conflat",
+		"hopping":        "hop",
+		"falling":        "fall",
+		"filing":         "file",
+		"happy":          "happi",
+		"relational":     "relat",
+		"generalization": "gener",
+		"connection":     "connect",
+		"connecting":     "connect",
+		"hopeful":        "hope",
+		"goodness":       "good",
+		"adjustment":     "adjust",
+		"controlling":    "control",
+		"go":             "go",
+		"http2":          "http2",
+		"café":           "café",
+	}
+
+	for word, want := range tests {
+		if got := stem(word); got != want {
+			t.Errorf("stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
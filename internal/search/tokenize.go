@@ -0,0 +1,64 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// stopWords are too common to be worth indexing.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "for": true, "if": true, "in": true, "into": true, "is": true, "it": true, "no": true,
+	"not": true, "of": true, "on": true, "or": true, "such": true, "that": true, "the": true,
+	"their": true, "then": true, "there": true, "these": true, "they": true, "this": true, "to": true,
+	"was": true, "will": true, "with": true,
+}
+
+// word is where a word is in some text, and the term it's indexed as or empty for stop words.
+type word struct {
+	start, end int
+	term       string
+}
+
+// words splits text into runs of letters and digits.
+func words(text string) []word {
+	var ws []word
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			ws = append(ws, newWord(text, start, i))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		ws = append(ws, newWord(text, start, len(text)))
+	}
+	return ws
+}
+
+func newWord(text string, start, end int) word {
+	w := word{start: start, end: end}
+	lower := strings.ToLower(text[start:end])
+	if !stopWords[lower] && (utf8.RuneCountInString(lower) > 1 || unicode.IsDigit(rune(lower[0]))) {
+		w.term = stem(lower)
+	}
+	return w
+}
+
+// Terms returns the terms text is indexed as, lower case and stemmed without stop words.
+func Terms(text string) []string {
+	var terms []string
+	for _, w := range words(text) {
+		if w.term != "" {
+			terms = append(terms, w.term)
+		}
+	}
+	return terms
+}
@@ -0,0 +1,107 @@
+// Package sitemap writes sitemaps for search engines, see https://www.sitemaps.org/protocol.html.
+package sitemap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ContentType is the media type of sitemaps.
+const ContentType = "application/xml; charset=utf-8"
+
+// MaxURLs is the most URLs a sitemap can have, larger sites need a sitemap index.
+const MaxURLs = 50000
+
+// URL is a page in a sitemap.
+type URL struct {
+	// Loc is the absolute URL of the page.
+	Loc string
+	// LastMod is when the page last changed, it's left out when it's zero.
+	LastMod time.Time
+	// Images are the absolute URLs of the images on the page.
+	Images []string
+}
+
+// Sitemap is a sitemap in a sitemap index.
+type Sitemap struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	ImageNS string   `xml:"xmlns:image,attr,omitempty"`
+	URLs    []xmlURL `xml:"url"`
+}
+
+type xmlURL struct {
+	Loc     string     `xml:"loc"`
+	LastMod string     `xml:"lastmod,omitempty"`
+	Images  []xmlImage `xml:"image:image"`
+}
+
+type xmlImage struct {
+	Loc string `xml:"image:loc"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []xmlSitemap `xml:"sitemap"`
+}
+
+type xmlSitemap struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+func lastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// WriteURLs writes a sitemap of urls.
+func WriteURLs(w io.Writer, urls []URL) error {
+	if len(urls) > MaxURLs {
+		return fmt.Errorf("%d urls is more than a sitemap can have", len(urls))
+	}
+
+	set := urlSet{URLs: []xmlURL{}}
+	for _, u := range urls {
+		xu := xmlURL{Loc: u.Loc, LastMod: lastMod(u.LastMod)}
+		for _, img := range u.Images {
+			xu.Images = append(xu.Images, xmlImage{Loc: img})
+		}
+		if len(xu.Images) > 0 {
+			set.ImageNS = "http://www.google.com/schemas/sitemap-image/1.1"
+		}
+		set.URLs = append(set.URLs, xu)
+	}
+
+	return write(w, set)
+}
+
+// WriteIndex writes a sitemap index of sitemaps.
+func WriteIndex(w io.Writer, sitemaps []Sitemap) error {
+	index := sitemapIndex{Sitemaps: []xmlSitemap{}}
+	for _, s := range sitemaps {
+		index.Sitemaps = append(index.Sitemaps, xmlSitemap{Loc: s.Loc, LastMod: lastMod(s.LastMod)})
+	}
+
+	return write(w, index)
+}
+
+func write(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encoding sitemap: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package sitemap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteURLs(t *testing.T) {
+	urls := []URL{
+		{Loc: "https://warrenb95.dev/"},
+		{
+			Loc:     "https://warrenb95.dev/blog/htmx_and_go",
+			LastMod: time.Date(2023, 11, 5, 9, 0, 0, 0, time.UTC),
+			Images:  []string{"https://warrenb95.dev/static/htmx.png"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteURLs(&buf, urls); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		xml.Header,
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:image="http://www.google.com/schemas/sitemap-image/1.1">`,
+		"<url>\n    <loc>https://warrenb95.dev/</loc>\n  </url>",
+		"<lastmod>2023-11-05T09:00:00Z</lastmod>",
+		"<image:image>\n      <image:loc>https://warrenb95.dev/static/htmx.png</image:loc>\n    </image:image>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("sitemap doesn't contain %q\n%s", want, out)
+		}
+	}
+
+	var parsed struct {
+		URLs []struct {
+			Loc string `xml:"loc"`
+		} `xml:"url"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("sitemap isn't valid xml: %v", err)
+	}
+	if len(parsed.URLs) != 2 {
+		t.Errorf("parsed %d urls, want 2", len(parsed.URLs))
+	}
+}
+
+func TestWriteURLsTooMany(t *testing.T) {
+	if err := WriteURLs(&bytes.Buffer{}, make([]URL, MaxURLs+1)); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestWriteIndex(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteIndex(&buf, []Sitemap{
+		{Loc: "https://warrenb95.dev/sitemap-1.xml", LastMod: time.Date(2023, 11, 5, 9, 0, 0, 0, time.UTC)},
+		{Loc: "https://warrenb95.dev/sitemap-2.xml"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`,
+		"<sitemap>\n    <loc>https://warrenb95.dev/sitemap-1.xml</loc>\n    <lastmod>2023-11-05T09:00:00Z</lastmod>\n  </sitemap>",
+		"<sitemap>\n    <loc>https://warrenb95.dev/sitemap-2.xml</loc>\n  </sitemap>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("index doesn't contain %q\n%s", want, out)
+		}
+	}
+}
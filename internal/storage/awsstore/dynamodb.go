@@ -0,0 +1,105 @@
+package awsstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/warrenb95/website/internal/blog"
+)
+
+// BlogStore is a blog.BlogStore backed by a DynamoDB table keyed on the blog slug.
+type BlogStore struct {
+	client *dynamodb.Client
+	table  string
+}
+
+func NewBlogStore(client *dynamodb.Client, table string) *BlogStore {
+	return &BlogStore{
+		client: client,
+		table:  table,
+	}
+}
+
+// List scans every page of the table, a single scan stops at 1 MB of items.
+func (s *BlogStore) List(ctx context.Context) ([]blog.Blog, error) {
+	paginator := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName: aws.String(s.table),
+	})
+
+	var blogs []blog.Blog
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("scanning %s table: %w", s.table, err)
+		}
+
+		var page []blog.Blog
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &page); err != nil {
+			return nil, fmt.Errorf("unmarshalling blogs: %w", err)
+		}
+		blogs = append(blogs, page...)
+	}
+
+	return blogs, nil
+}
+
+func (s *BlogStore) Get(ctx context.Context, slug string) (blog.Blog, error) {
+	out, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(s.table),
+		Key:       slugKey(slug),
+	})
+	if err != nil {
+		return blog.Blog{}, fmt.Errorf("getting blog %q: %w", slug, err)
+	}
+	if out.Item == nil {
+		return blog.Blog{}, blog.ErrNotFound
+	}
+
+	var b blog.Blog
+	if err := attributevalue.UnmarshalMap(out.Item, &b); err != nil {
+		return blog.Blog{}, fmt.Errorf("unmarshalling blog %q: %w", slug, err)
+	}
+
+	return b, nil
+}
+
+func (s *BlogStore) Put(ctx context.Context, b blog.Blog) error {
+	item, err := attributevalue.MarshalMap(b)
+	if err != nil {
+		return fmt.Errorf("marshalling blog %q: %w", b.Slug, err)
+	}
+
+	_, err = s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("putting blog %q: %w", b.Slug, err)
+	}
+
+	return nil
+}
+
+func (s *BlogStore) Delete(ctx context.Context, slug string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.table),
+		Key:       slugKey(slug),
+	})
+	if err != nil {
+		return fmt.Errorf("deleting blog %q: %w", slug, err)
+	}
+
+	return nil
+}
+
+// slugKey is the key for the blog, the slug is stored in the "title" attribute.
+func slugKey(slug string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"title": &types.AttributeValueMemberS{Value: slug},
+	}
+}
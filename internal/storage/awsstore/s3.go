@@ -0,0 +1,146 @@
+package awsstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/warrenb95/website/internal/blog"
+)
+
+// ContentStore is a blog.ContentStore backed by an S3 bucket.
+// Markdown lives at <prefix><slug>.md and images at <prefix>images/<name>.
+type ContentStore struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func NewContentStore(client *s3.Client, bucket, prefix string) *ContentStore {
+	return &ContentStore{
+		client: client,
+		bucket: bucket,
+		prefix: prefix,
+	}
+}
+
+func (s *ContentStore) ListMarkdown(ctx context.Context) ([]string, error) {
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.prefix),
+	})
+
+	var slugs []string
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing objects in %s: %w", s.bucket, err)
+		}
+
+		for _, object := range out.Contents {
+			name := strings.TrimPrefix(aws.ToString(object.Key), s.prefix)
+			// Skip the images and anything else that isn't a blog.
+			if strings.Contains(name, "/") || !strings.HasSuffix(name, ".md") {
+				continue
+			}
+			slugs = append(slugs, strings.TrimSuffix(name, ".md"))
+		}
+	}
+
+	return slugs, nil
+}
+
+func (s *ContentStore) GetMarkdown(ctx context.Context, slug string) ([]byte, error) {
+	if !blog.ValidSlug(slug) {
+		return nil, blog.ErrNotFound
+	}
+	return s.get(ctx, s.markdownKey(slug))
+}
+
+// MarkdownVersion is the object's ETag.
+func (s *ContentStore) MarkdownVersion(ctx context.Context, slug string) (string, error) {
+	if !blog.ValidSlug(slug) {
+		return "", blog.ErrNotFound
+	}
+
+	key := s.markdownKey(slug)
+	object, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var nf *types.NotFound
+		if errors.As(err, &nf) {
+			return "", blog.ErrNotFound
+		}
+		return "", fmt.Errorf("getting object %q metadata: %w", key, err)
+	}
+
+	return aws.ToString(object.ETag), nil
+}
+
+func (s *ContentStore) PutMarkdown(ctx context.Context, slug string, content []byte) error {
+	if !blog.ValidSlug(slug) {
+		return fmt.Errorf("invalid slug %q", slug)
+	}
+	return s.put(ctx, s.markdownKey(slug), content, "text/markdown")
+}
+
+func (s *ContentStore) GetImage(ctx context.Context, name string) ([]byte, error) {
+	return s.get(ctx, s.imageKey(name))
+}
+
+func (s *ContentStore) PutImage(ctx context.Context, name string, data []byte, contentType string) error {
+	return s.put(ctx, s.imageKey(name), data, contentType)
+}
+
+func (s *ContentStore) markdownKey(slug string) string {
+	return fmt.Sprintf("%s%s.md", s.prefix, slug)
+}
+
+func (s *ContentStore) imageKey(name string) string {
+	return fmt.Sprintf("%simages/%s", s.prefix, name)
+}
+
+func (s *ContentStore) get(ctx context.Context, key string) ([]byte, error) {
+	object, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, blog.ErrNotFound
+		}
+		return nil, fmt.Errorf("getting object %q: %w", key, err)
+	}
+	defer object.Body.Close()
+
+	b, err := io.ReadAll(object.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading object %q: %w", key, err)
+	}
+
+	return b, nil
+}
+
+func (s *ContentStore) put(ctx context.Context, key string, data []byte, contentType string) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("putting object %q: %w", key, err)
+	}
+
+	return nil
+}
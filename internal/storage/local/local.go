@@ -0,0 +1,240 @@
+// Package local serves blogs from markdown files on disk so the site can run without AWS.
+//
+// Each blog is a <slug>.md file in the content directory, the file name being the blog's key
+// the same way the DynamoDB table is keyed. The metadata comes from the file's front matter.
+// Images live in the images/ sub directory.
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/warrenb95/website/internal/blog"
+)
+
+// Store is both a blog.BlogStore and blog.ContentStore.
+type Store struct {
+	dir    string
+	logger *logrus.Logger
+}
+
+func NewStore(dir string, logger *logrus.Logger) *Store {
+	return &Store{dir: dir, logger: logger}
+}
+
+// List returns the blog in every markdown file. Files that aren't blogs, with a name that isn't a
+// slug or broken front matter, are skipped with a warning rather than failing the whole site.
+func (s *Store) List(ctx context.Context) ([]blog.Blog, error) {
+	slugs, err := s.ListMarkdown(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	blogs := make([]blog.Blog, 0, len(slugs))
+	for _, slug := range slugs {
+		if !blog.ValidSlug(slug) {
+			s.logger.WithField("slug", slug).Warn("Skipping markdown file whose name isn't a slug")
+			continue
+		}
+		b, err := s.Get(ctx, slug)
+		if errors.Is(err, blog.ErrNotFound) {
+			// Deleted since it was listed.
+			continue
+		}
+		if err != nil {
+			s.logger.WithError(err).WithField("slug", slug).Warn("Skipping markdown file with broken front matter")
+			continue
+		}
+		blogs = append(blogs, b)
+	}
+
+	return blogs, nil
+}
+
+func (s *Store) Get(_ context.Context, slug string) (blog.Blog, error) {
+	fm, _, err := s.read(slug)
+	if err != nil {
+		return blog.Blog{}, err
+	}
+
+	b := blog.Blog{Slug: slug}
+	fm.Apply(&b)
+
+	return b, nil
+}
+
+// Put writes the blog metadata to the front matter, keeping the rest of the file.
+func (s *Store) Put(_ context.Context, b blog.Blog) error {
+	fm, body, err := s.read(b.Slug)
+	if err != nil && !errors.Is(err, blog.ErrNotFound) {
+		return err
+	}
+
+	if err := fm.Merge(b); err != nil {
+		return err
+	}
+
+	content, err := blog.FormatFrontMatter(fm, body)
+	if err != nil {
+		return err
+	}
+
+	return s.write(b.Slug, content)
+}
+
+func (s *Store) Delete(_ context.Context, slug string) error {
+	p, err := s.markdownPath(slug)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("deleting %s: %w", p, err)
+	}
+
+	return nil
+}
+
+func (s *Store) ListMarkdown(_ context.Context) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.md"))
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", s.dir, err)
+	}
+
+	slugs := make([]string, 0, len(paths))
+	for _, p := range paths {
+		slugs = append(slugs, strings.TrimSuffix(filepath.Base(p), ".md"))
+	}
+
+	return slugs, nil
+}
+
+// GetMarkdown returns the whole file, front matter included.
+func (s *Store) GetMarkdown(_ context.Context, slug string) ([]byte, error) {
+	p, err := s.markdownPath(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, blog.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", p, err)
+	}
+
+	return content, nil
+}
+
+// MarkdownVersion is the file's modified time and size.
+func (s *Store) MarkdownVersion(_ context.Context, slug string) (string, error) {
+	p, err := s.markdownPath(slug)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", blog.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", p, err)
+	}
+
+	return strconv.FormatInt(info.ModTime().UnixNano(), 16) + "-" + strconv.FormatInt(info.Size(), 16), nil
+}
+
+func (s *Store) PutMarkdown(_ context.Context, slug string, content []byte) error {
+	return s.write(slug, content)
+}
+
+func (s *Store) GetImage(_ context.Context, name string) ([]byte, error) {
+	p, err := s.imagePath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, blog.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", p, err)
+	}
+
+	return data, nil
+}
+
+func (s *Store) PutImage(_ context.Context, name string, data []byte, _ string) error {
+	p, err := s.imagePath(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(p), err)
+	}
+	if err := os.WriteFile(p, data, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", p, err)
+	}
+
+	return nil
+}
+
+func (s *Store) read(slug string) (blog.FrontMatter, []byte, error) {
+	content, err := s.GetMarkdown(context.Background(), slug)
+	if err != nil {
+		return blog.FrontMatter{}, nil, err
+	}
+
+	fm, body, err := blog.ParseFrontMatter(content)
+	if err != nil {
+		return blog.FrontMatter{}, nil, fmt.Errorf("%s: %w", slug, err)
+	}
+
+	return fm, body, nil
+}
+
+func (s *Store) write(slug string, content []byte) error {
+	p, err := s.markdownPath(slug)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", s.dir, err)
+	}
+	if err := os.WriteFile(p, content, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", p, err)
+	}
+
+	return nil
+}
+
+func (s *Store) markdownPath(slug string) (string, error) {
+	if !blog.ValidSlug(slug) {
+		return "", blog.ErrNotFound
+	}
+	return filepath.Join(s.dir, slug+".md"), nil
+}
+
+func (s *Store) imagePath(name string) (string, error) {
+	if !validName(name) {
+		return "", blog.ErrNotFound
+	}
+	return filepath.Join(s.dir, "images", name), nil
+}
+
+// validName stops image names escaping the content directory.
+func validName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
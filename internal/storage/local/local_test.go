@@ -0,0 +1,127 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+
+	"github.com/warrenb95/website/internal/blog"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	logger, _ := test.NewNullLogger()
+	s := NewStore(dir, logger)
+
+	md := "---\ntitle: Hello World\ndate: 2023-11-03T20:00:00Z\n---\n# Hello\n"
+	if err := s.PutMarkdown(ctx, "hello_world", []byte(md)); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := s.Get(ctx, "hello_world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := blog.Blog{Slug: "hello_world", Title: "Hello World", Uploaded: "2023-11-03T20:00:00+00:00"}
+	if !reflect.DeepEqual(b, want) {
+		t.Errorf("got %+v, want %+v", b, want)
+	}
+	version, err := s.MarkdownVersion(ctx, "hello_world")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Writing the metadata keeps the body and the front matter a Blog doesn't have.
+	b.Summary = "Saying hello."
+	if err := s.Put(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	content, err := s.GetMarkdown(ctx, "hello_world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm, body, err := blog.ParseFrontMatter(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm.Title != "Hello World" || fm.Summary != "Saying hello." || string(body) != "# Hello\n" {
+		t.Errorf("front matter = %+v, body = %q", fm, body)
+	}
+	if v, err := s.MarkdownVersion(ctx, "hello_world"); err != nil || v == version {
+		t.Errorf("version = %q, %v after writing, was %q", v, err, version)
+	}
+
+	blogs, err := s.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0].Summary != "Saying hello." {
+		t.Errorf("list = %+v", blogs)
+	}
+
+	if err := s.Delete(ctx, "hello_world"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "hello_world"); !errors.Is(err, blog.ErrNotFound) {
+		t.Errorf("get after delete = %v, want not found", err)
+	}
+	if _, err := s.MarkdownVersion(ctx, "hello_world"); !errors.Is(err, blog.ErrNotFound) {
+		t.Errorf("version after delete = %v, want not found", err)
+	}
+}
+
+func TestStoreRejectsPathsOutsideDir(t *testing.T) {
+	ctx := context.Background()
+	parent := t.TempDir()
+	if err := os.WriteFile(filepath.Join(parent, "secret.md"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	logger, _ := test.NewNullLogger()
+	s := NewStore(filepath.Join(parent, "content"), logger)
+
+	for _, slug := range []string{"../secret", "..", "a/b", `a\b`, ""} {
+		if _, err := s.GetMarkdown(ctx, slug); !errors.Is(err, blog.ErrNotFound) {
+			t.Errorf("GetMarkdown(%q) = %v, want not found", slug, err)
+		}
+		if _, err := s.GetImage(ctx, slug); !errors.Is(err, blog.ErrNotFound) {
+			t.Errorf("GetImage(%q) = %v, want not found", slug, err)
+		}
+	}
+}
+
+func TestStoreListSkipsBrokenFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"hello_world.md":  "---\ntitle: Hello World\n---\n# Hello\n",
+		"Not A Slug.md":   "---\ntitle: Stray\n---\n",
+		"broken_front.md": "---\ntitle: [unclosed\n---\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logger, hook := test.NewNullLogger()
+	s := NewStore(dir, logger)
+
+	blogs, err := s.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != 1 || blogs[0].Slug != "hello_world" {
+		t.Errorf("list = %+v, want only hello_world", blogs)
+	}
+	if n := len(hook.AllEntries()); n != 2 {
+		t.Errorf("logged %d warnings, want 2", n)
+	}
+
+	if _, err := s.Get(ctx, "broken_front"); err == nil {
+		t.Error("got broken_front without an error")
+	}
+}
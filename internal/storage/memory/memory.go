@@ -0,0 +1,164 @@
+// Package memory is an in-memory blog store, used in tests and for running the site with no backend.
+package memory
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/warrenb95/website/internal/blog"
+)
+
+// Store is both a blog.BlogStore and blog.ContentStore.
+type Store struct {
+	mu       sync.RWMutex
+	blogs    map[string]blog.Blog
+	markdown map[string][]byte
+	images   map[string][]byte
+
+	// Err is returned from every method when set, to simulate a failing backend.
+	Err error
+}
+
+func NewStore() *Store {
+	return &Store{
+		blogs:    make(map[string]blog.Blog),
+		markdown: make(map[string][]byte),
+		images:   make(map[string][]byte),
+	}
+}
+
+func (s *Store) List(_ context.Context) ([]blog.Blog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	blogs := make([]blog.Blog, 0, len(s.blogs))
+	for _, b := range s.blogs {
+		blogs = append(blogs, b)
+	}
+
+	return blogs, nil
+}
+
+func (s *Store) Get(_ context.Context, slug string) (blog.Blog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.Err != nil {
+		return blog.Blog{}, s.Err
+	}
+
+	b, ok := s.blogs[slug]
+	if !ok {
+		return blog.Blog{}, blog.ErrNotFound
+	}
+
+	return b, nil
+}
+
+func (s *Store) Put(_ context.Context, b blog.Blog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Err != nil {
+		return s.Err
+	}
+
+	s.blogs[b.Slug] = b
+
+	return nil
+}
+
+func (s *Store) Delete(_ context.Context, slug string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Err != nil {
+		return s.Err
+	}
+
+	delete(s.blogs, slug)
+	delete(s.markdown, slug)
+
+	return nil
+}
+
+func (s *Store) ListMarkdown(_ context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	slugs := make([]string, 0, len(s.markdown))
+	for slug := range s.markdown {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	return slugs, nil
+}
+
+func (s *Store) GetMarkdown(_ context.Context, slug string) ([]byte, error) {
+	return s.get(s.markdown, slug)
+}
+
+// MarkdownVersion hashes the markdown.
+func (s *Store) MarkdownVersion(ctx context.Context, slug string) (string, error) {
+	md, err := s.GetMarkdown(ctx, slug)
+	if err != nil {
+		return "", err
+	}
+
+	h := fnv.New64a()
+	h.Write(md)
+	return strconv.FormatUint(h.Sum64(), 16), nil
+}
+
+func (s *Store) PutMarkdown(_ context.Context, slug string, content []byte) error {
+	return s.put(s.markdown, slug, content)
+}
+
+func (s *Store) GetImage(_ context.Context, name string) ([]byte, error) {
+	return s.get(s.images, name)
+}
+
+func (s *Store) PutImage(_ context.Context, name string, data []byte, _ string) error {
+	return s.put(s.images, name, data)
+}
+
+func (s *Store) get(m map[string][]byte, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	data, ok := m[key]
+	if !ok {
+		return nil, blog.ErrNotFound
+	}
+
+	return append([]byte(nil), data...), nil
+}
+
+func (s *Store) put(m map[string][]byte, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.Err != nil {
+		return s.Err
+	}
+
+	m[key] = append([]byte(nil), data...)
+
+	return nil
+}
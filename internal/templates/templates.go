@@ -0,0 +1,152 @@
+// Package templates parses the HTML views once and serves them to the handlers.
+package templates
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Registry holds the parsed templates.
+type Registry struct {
+	fsys  fs.FS
+	pages []string
+
+	mu   sync.RWMutex
+	tmpl *template.Template
+}
+
+// New parses every *.html file in fsys and checks each of pages is defined,
+// so a broken view stops the site starting rather than failing requests.
+func New(fsys fs.FS, pages ...string) (*Registry, error) {
+	r := &Registry{
+		fsys:  fsys,
+		pages: pages,
+	}
+
+	tmpl, err := r.parse()
+	if err != nil {
+		return nil, err
+	}
+	r.tmpl = tmpl
+
+	return r, nil
+}
+
+// Execute renders the named template into a buffer first so a failed template doesn't send half a page.
+func (r *Registry) Execute(w io.Writer, name string, data interface{}) error {
+	r.mu.RLock()
+	tmpl := r.tmpl
+	r.mu.RUnlock()
+
+	var b bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&b, name, data); err != nil {
+		return err
+	}
+
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// Has reports whether the named template is defined.
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.tmpl.Lookup(name) != nil
+}
+
+// Reload re-parses the templates. The current templates are kept if parsing fails.
+func (r *Registry) Reload() error {
+	tmpl, err := r.parse()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.tmpl = tmpl
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Watch polls the templates for changes every interval and reloads them until ctx is done.
+// It's meant for development with an os.DirFS, embedded files never change.
+// reloaded is called after every reload with the parse error, if any.
+func (r *Registry) Watch(ctx context.Context, interval time.Duration, reloaded func(error)) {
+	last, err := r.modified()
+	if err != nil {
+		reloaded(err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		mod, err := r.modified()
+		if err != nil {
+			reloaded(err)
+			continue
+		}
+		if mod == last {
+			continue
+		}
+		last = mod
+
+		reloaded(r.Reload())
+	}
+}
+
+// funcs are the functions the templates can call.
+var funcs = template.FuncMap{
+	// pathEscape escapes a path segment, html/template only normalises URLs so a tag like "C#" would
+	// otherwise lose everything after the "#".
+	"pathEscape": url.PathEscape,
+}
+
+func (r *Registry) parse() (*template.Template, error) {
+	tmpl, err := template.New("").Funcs(funcs).ParseFS(r.fsys, "*.html")
+	if err != nil {
+		return nil, fmt.Errorf("parsing templates: %w", err)
+	}
+
+	for _, page := range r.pages {
+		if tmpl.Lookup(page) == nil {
+			return nil, fmt.Errorf("missing %s template", page)
+		}
+	}
+
+	return tmpl, nil
+}
+
+// modified summarises the templates' names, sizes and modification times,
+// changing whenever a template is added, removed or edited.
+func (r *Registry) modified() (string, error) {
+	matches, err := fs.Glob(r.fsys, "*.html")
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	for _, name := range matches {
+		info, err := fs.Stat(r.fsys, name)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", name, info.Size(), info.ModTime().UnixNano())
+	}
+
+	return b.String(), nil
+}
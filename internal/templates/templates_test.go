@@ -0,0 +1,140 @@
+package templates
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	tests := map[string]struct {
+		files   fstest.MapFS
+		pages   []string
+		wantErr string
+	}{
+		"valid templates": {
+			files: fstest.MapFS{
+				"index.html": {Data: []byte(`{{template "head" .}}index`)},
+				"Head.html":  {Data: []byte(`{{define "head"}}head{{end}}`)},
+			},
+			pages: []string{"index.html"},
+		},
+		"syntax error": {
+			files: fstest.MapFS{
+				"index.html": {Data: []byte(`{{if}}`)},
+			},
+			wantErr: "parsing templates",
+		},
+		"missing page": {
+			files: fstest.MapFS{
+				"index.html": {Data: []byte(`index`)},
+			},
+			pages:   []string{"index.html", "about.html"},
+			wantErr: "missing about.html template",
+		},
+		"no templates": {
+			files:   fstest.MapFS{},
+			wantErr: "parsing templates",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(tc.files, tc.pages...)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	r, err := New(fstest.MapFS{
+		"index.html": {Data: []byte(`<h1>{{.Title}}</h1>{{.Missing.Field}}`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := r.Execute(&b, "index.html", struct{ Title string }{"title"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if b.Len() != 0 {
+		t.Errorf("wrote %q for a failed template", b.String())
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "before", time.Unix(1, 0))
+
+	r, err := New(os.DirFS(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloads := make(chan error)
+	go r.Watch(ctx, time.Millisecond, func(err error) {
+		select {
+		case reloads <- err:
+		case <-ctx.Done():
+		}
+	})
+
+	// Give the watcher time to take its first snapshot.
+	time.Sleep(20 * time.Millisecond)
+
+	writeTemplate(t, dir, "{{if}}", time.Unix(2, 0))
+	if err := <-reloads; err == nil {
+		t.Fatal("expected the broken template to fail to reload")
+	}
+	assertRenders(t, r, "before")
+
+	writeTemplate(t, dir, "after", time.Unix(3, 0))
+	if err := <-reloads; err != nil {
+		t.Fatalf("unexpected reload error: %v", err)
+	}
+	assertRenders(t, r, "after")
+}
+
+func writeTemplate(t *testing.T, dir, content string, modTime time.Time) {
+	t.Helper()
+
+	// Written beside it and renamed into place, so the watcher never sees it half written.
+	tmp := filepath.Join(dir, "index.html.tmp")
+	if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(tmp, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, "index.html")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertRenders(t *testing.T, r *Registry, want string) {
+	t.Helper()
+
+	var b strings.Builder
+	if err := r.Execute(&b, "index.html", nil); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != want {
+		t.Errorf("rendered %q, want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,107 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/warrenb95/website/internal/blog"
+)
+
+// Heading is a heading in a document, Level is 1 for an <h1>.
+type Heading struct {
+	Level int
+	ID    string
+	Text  string
+}
+
+// HeadingAnchorClass is the class of the permalinks appended to headings.
+const HeadingAnchorClass = "heading-anchor"
+
+// IsHeadingAnchor reports whether n is a permalink appended to a heading.
+func IsHeadingAnchor(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.DataAtom == atom.A && hasClass(n, HeadingAnchorClass)
+}
+
+// headingAnchors gives every heading an id slugified from its text, unless it has one from {#id} in
+// the markdown, and appends a permalink to it. Repeated ids get _1, _2 and so on.
+func headingAnchors(d *Document) {
+	ids := make(map[string]bool)
+	walk(d.Body, func(n *html.Node) bool {
+		level := headingLevel(n)
+		if level == 0 {
+			return true
+		}
+		d.Headings = append(d.Headings, anchorHeading(n, level, ids))
+		return false
+	})
+}
+
+// headingLevel returns the level of an <h1> to <h6>, or 0 for any other node.
+func headingLevel(n *html.Node) int {
+	if n.Type != html.ElementNode {
+		return 0
+	}
+	switch n.DataAtom {
+	case atom.H1:
+		return 1
+	case atom.H2:
+		return 2
+	case atom.H3:
+		return 3
+	case atom.H4:
+		return 4
+	case atom.H5:
+		return 5
+	case atom.H6:
+		return 6
+	}
+	return 0
+}
+
+// anchorHeading gives the heading its id and permalink. ids are the ids taken so far.
+func anchorHeading(n *html.Node, level int, ids map[string]bool) Heading {
+	h := Heading{Level: level, Text: strings.Join(strings.Fields(nodeText(n)), " ")}
+
+	h.ID, _ = attr(n, "id")
+	if h.ID == "" {
+		h.ID = blog.Slugify(h.Text)
+		if h.ID == "" {
+			h.ID = "section"
+		}
+	}
+	for i, id := 1, h.ID; ids[h.ID]; i++ {
+		h.ID = fmt.Sprintf("%s_%d", id, i)
+	}
+	ids[h.ID] = true
+	setAttr(n, "id", h.ID)
+
+	anchor := &html.Node{
+		Type:     html.ElementNode,
+		Data:     "a",
+		DataAtom: atom.A,
+		Attr: []html.Attribute{
+			{Key: "class", Val: HeadingAnchorClass},
+			{Key: "href", Val: "#" + h.ID},
+			{Key: "aria-label", Val: "Link to this section"},
+		},
+	}
+	anchor.AppendChild(&html.Node{Type: html.TextNode, Data: "#"})
+	n.AppendChild(anchor)
+
+	return h
+}
+
+// nodeText returns the text in n.
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(nodeText(c))
+	}
+	return sb.String()
+}
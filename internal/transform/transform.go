@@ -0,0 +1,187 @@
+// Package transform post-processes rendered markdown with named transformers run in order over
+// its HTML tree, like adding Bootstrap's classes or anchoring the headings.
+package transform
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Document is an HTML fragment being transformed.
+type Document struct {
+	// Body holds the fragment's nodes.
+	Body *html.Node
+	// Base is prepended to links and images to the site's own pages, making them absolute, when it's set.
+	Base string
+	// Host is the site's host, links to other hosts are external. Every absolute link is when it's empty.
+	Host string
+	// Headings are the headings in order, found by the heading-anchors transformer.
+	Headings []Heading
+}
+
+// Parse parses an HTML fragment as if it's in a <body>.
+func Parse(r io.Reader) (*Document, error) {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(r, body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing html: %w", err)
+	}
+	for _, n := range nodes {
+		body.AppendChild(n)
+	}
+	return &Document{Body: body}, nil
+}
+
+// Render writes the fragment.
+func (d *Document) Render(w io.Writer) error {
+	for n := d.Body.FirstChild; n != nil; n = n.NextSibling {
+		if err := html.Render(w, n); err != nil {
+			return fmt.Errorf("rendering html: %w", err)
+		}
+	}
+	return nil
+}
+
+// Transformer changes a document in place.
+type Transformer func(d *Document)
+
+var registry = map[string]Transformer{}
+
+// Register adds a transformer for pipelines to use by name, replacing any with the same name.
+// It's meant to be called from init functions.
+func Register(name string, t Transformer) {
+	registry[name] = t
+}
+
+// Registered reports whether there's a transformer called name.
+func Registered(name string) bool {
+	_, ok := registry[name]
+	return ok
+}
+
+// Names returns the names of every registered transformer, sorted.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Pipeline runs transformers in order.
+type Pipeline struct {
+	transformers []Transformer
+}
+
+// New returns a pipeline running the named transformers in order.
+// Unknown names are skipped, check them with Registered.
+func New(names ...string) *Pipeline {
+	p := &Pipeline{}
+	for _, name := range names {
+		if t, ok := registry[name]; ok {
+			p.transformers = append(p.transformers, t)
+		}
+	}
+	return p
+}
+
+// Apply runs the pipeline's transformers on the document.
+func (p *Pipeline) Apply(d *Document) {
+	for _, t := range p.transformers {
+		t(d)
+	}
+}
+
+// walk calls fn for n and every node under it, depth first, skipping the children of nodes fn returns false for.
+// fn can change a node's children and attributes but not its siblings.
+func walk(n *html.Node, fn func(*html.Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+// elements returns every element with one of the atoms under n, outermost first.
+func elements(n *html.Node, atoms ...atom.Atom) []*html.Node {
+	var found []*html.Node
+	walk(n, func(n *html.Node) bool {
+		if n.Type == html.ElementNode {
+			for _, a := range atoms {
+				if n.DataAtom == a {
+					found = append(found, n)
+				}
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// attr returns the value of the node's attribute and whether it has it.
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// setAttr sets the node's attribute, adding it if it's not there.
+func setAttr(n *html.Node, key, val string) {
+	for i, a := range n.Attr {
+		if a.Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
+
+// hasClass reports whether the node has the class.
+func hasClass(n *html.Node, class string) bool {
+	classes, _ := attr(n, "class")
+	for _, c := range strings.Fields(classes) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// addClass adds the classes the node doesn't have already.
+func addClass(n *html.Node, classes ...string) {
+	existing, _ := attr(n, "class")
+	fields := strings.Fields(existing)
+	for _, c := range classes {
+		if !hasClass(n, c) {
+			fields = append(fields, c)
+		}
+	}
+	setAttr(n, "class", strings.Join(fields, " "))
+}
+
+// wrap puts n in the wrapper element, where n was.
+func wrap(n *html.Node, wrapper *html.Node) {
+	n.Parent.InsertBefore(wrapper, n)
+	n.Parent.RemoveChild(n)
+	wrapper.AppendChild(n)
+}
+
+// element returns a new element with the class.
+func element(a atom.Atom, class string) *html.Node {
+	return &html.Node{
+		Type:     html.ElementNode,
+		Data:     a.String(),
+		DataAtom: a,
+		Attr:     []html.Attribute{{Key: "class", Val: class}},
+	}
+}
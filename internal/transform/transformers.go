@@ -0,0 +1,125 @@
+package transform
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Defaults are the transformers a site runs unless it's configured otherwise, in order.
+var Defaults = []string{
+	"absolute-urls",
+	"image-classes",
+	"lazy-images",
+	"external-links",
+	"heading-anchors",
+	"tables",
+	"code-blocks",
+}
+
+func init() {
+	Register("absolute-urls", absoluteURLs)
+	Register("image-classes", imageClasses)
+	Register("lazy-images", lazyImages)
+	Register("external-links", externalLinks)
+	Register("heading-anchors", headingAnchors)
+	Register("tables", tables)
+	Register("code-blocks", codeBlocks)
+}
+
+// absoluteURLs prepends the document's Base to the links and images to the site's own pages.
+func absoluteURLs(d *Document) {
+	if d.Base == "" {
+		return
+	}
+	walk(d.Body, func(n *html.Node) bool {
+		for i, a := range n.Attr {
+			if (a.Key == "href" || a.Key == "src") && strings.HasPrefix(a.Val, "/") && !strings.HasPrefix(a.Val, "//") {
+				n.Attr[i].Val = d.Base + a.Val
+			}
+		}
+		return true
+	})
+}
+
+// imageClasses makes images responsive.
+func imageClasses(d *Document) {
+	for _, img := range elements(d.Body, atom.Img) {
+		addClass(img, "img-fluid")
+	}
+}
+
+// lazyImages has browsers load and decode images as they're scrolled to, unless the markdown says otherwise.
+func lazyImages(d *Document) {
+	for _, img := range elements(d.Body, atom.Img) {
+		if _, ok := attr(img, "loading"); !ok {
+			setAttr(img, "loading", "lazy")
+		}
+		if _, ok := attr(img, "decoding"); !ok {
+			setAttr(img, "decoding", "async")
+		}
+	}
+}
+
+// externalLinks opens links to other sites in a new tab, without giving them the window or a referrer.
+func externalLinks(d *Document) {
+	for _, a := range elements(d.Body, atom.A) {
+		href, _ := attr(a, "href")
+		if !isExternal(href, d.Host) {
+			continue
+		}
+		setAttr(a, "target", "_blank")
+		setAttr(a, "rel", "noopener noreferrer")
+	}
+}
+
+// isExternal reports whether href links to a host other than host.
+func isExternal(href, host string) bool {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	return host == "" || !strings.EqualFold(u.Host, host)
+}
+
+// tables styles tables with Bootstrap, scrolling sideways on small screens.
+func tables(d *Document) {
+	for _, table := range elements(d.Body, atom.Table) {
+		if table.Parent.DataAtom == atom.Div && hasClass(table.Parent, "table-responsive") {
+			continue
+		}
+		// Line numbered code blocks are laid out with tables.
+		if hasClass(table, "lntable") {
+			continue
+		}
+		addClass(table, "table", "table-dark", "table-striped")
+		wrap(table, element(atom.Div, "table-responsive"))
+	}
+}
+
+// codeBlocks puts every code block in a <figure class="code-block">, as code blocks with titles are
+// already, so they're all styled alike.
+func codeBlocks(d *Document) {
+	var blocks []*html.Node
+	walk(d.Body, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+		switch {
+		case n.DataAtom == atom.Figure && hasClass(n, "code-block"):
+			return false
+		case n.DataAtom == atom.Div && hasClass(n, "chroma"), n.DataAtom == atom.Pre:
+			blocks = append(blocks, n)
+			return false
+		}
+		return true
+	})
+	for _, n := range blocks {
+		wrap(n, element(atom.Figure, "code-block"))
+	}
+}
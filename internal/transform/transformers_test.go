@@ -0,0 +1,139 @@
+package transform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// apply runs the named transformers over in and returns the HTML they made.
+func apply(t *testing.T, in, base, host string, names ...string) (string, *Document) {
+	t.Helper()
+
+	d, err := Parse(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Base, d.Host = base, host
+	New(names...).Apply(d)
+
+	var sb strings.Builder
+	if err := d.Render(&sb); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String(), d
+}
+
+func TestTransformers(t *testing.T) {
+	tests := map[string]struct {
+		transformer string
+		in          string
+		base        string
+		host        string
+		want        string
+	}{
+		"absolute urls": {
+			transformer: "absolute-urls",
+			in:          `<a href="/about">a</a><img src="/static/a.png"/><a href="//cdn.example.com/x">b</a><a href="#top">c</a>`,
+			base:        "https://warrenb95.dev",
+			want:        `<a href="https://warrenb95.dev/about">a</a><img src="https://warrenb95.dev/static/a.png"/><a href="//cdn.example.com/x">b</a><a href="#top">c</a>`,
+		},
+		"absolute urls without a base": {
+			transformer: "absolute-urls",
+			in:          `<a href="/about">a</a>`,
+			want:        `<a href="/about">a</a>`,
+		},
+		"image classes": {
+			transformer: "image-classes",
+			in:          `<img src="a.png"/><img src="b.png" class="rounded img-fluid"/>`,
+			want:        `<img src="a.png" class="img-fluid"/><img src="b.png" class="rounded img-fluid"/>`,
+		},
+		"lazy images": {
+			transformer: "lazy-images",
+			in:          `<img src="a.png"/><img src="b.png" loading="eager"/>`,
+			want:        `<img src="a.png" loading="lazy" decoding="async"/><img src="b.png" loading="eager" decoding="async"/>`,
+		},
+		"external links": {
+			transformer: "external-links",
+			in:          `<a href="https://example.com">a</a><a href="https://WarrenB95.dev/about">b</a><a href="/about">c</a><a href="#top">d</a><a href="mailto:me@example.com">e</a>`,
+			host:        "warrenb95.dev",
+			want:        `<a href="https://example.com" target="_blank" rel="noopener noreferrer">a</a><a href="https://WarrenB95.dev/about">b</a><a href="/about">c</a><a href="#top">d</a><a href="mailto:me@example.com">e</a>`,
+		},
+		"external links without a host": {
+			transformer: "external-links",
+			in:          `<a href="https://warrenb95.dev/about">a</a><a href="/about">b</a>`,
+			want:        `<a href="https://warrenb95.dev/about" target="_blank" rel="noopener noreferrer">a</a><a href="/about">b</a>`,
+		},
+		"heading anchors": {
+			transformer: "heading-anchors",
+			in:          `<h2>Set up</h2><h2 id="mine">Set up</h2><h3>Set up</h3>`,
+			want: `<h2 id="set_up">Set up<a class="heading-anchor" href="#set_up" aria-label="Link to this section">#</a></h2>` +
+				`<h2 id="mine">Set up<a class="heading-anchor" href="#mine" aria-label="Link to this section">#</a></h2>` +
+				`<h3 id="set_up_1">Set up<a class="heading-anchor" href="#set_up_1" aria-label="Link to this section">#</a></h3>`,
+		},
+		"tables": {
+			transformer: "tables",
+			in:          `<table><tr><td>1</td></tr></table><div class="chroma"><table class="lntable"><tr><td>1</td></tr></table></div>`,
+			want: `<div class="table-responsive"><table class="table table-dark table-striped"><tbody><tr><td>1</td></tr></tbody></table></div>` +
+				`<div class="chroma"><table class="lntable"><tbody><tr><td>1</td></tr></tbody></table></div>`,
+		},
+		"code blocks": {
+			transformer: "code-blocks",
+			in:          `<pre><code>a</code></pre><div class="chroma"><pre class="chroma">b</pre></div><figure class="code-block"><pre class="chroma">c</pre></figure>`,
+			want: `<figure class="code-block"><pre><code>a</code></pre></figure>` +
+				`<figure class="code-block"><div class="chroma"><pre class="chroma">b</pre></div></figure>` +
+				`<figure class="code-block"><pre class="chroma">c</pre></figure>`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if !Registered(tc.transformer) {
+				t.Fatalf("%q isn't registered", tc.transformer)
+			}
+			got, _ := apply(t, tc.in, tc.base, tc.host, tc.transformer)
+			if got != tc.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHeadings(t *testing.T) {
+	_, d := apply(t, "<h1>Intro</h1><p>Hi</p><h2>The <code>go</code> tool</h2><h6></h6>", "", "", "heading-anchors")
+
+	want := []Heading{
+		{Level: 1, ID: "intro", Text: "Intro"},
+		{Level: 2, ID: "the_go_tool", Text: "The go tool"},
+		{Level: 6, ID: "section", Text: ""},
+	}
+	if !reflect.DeepEqual(d.Headings, want) {
+		t.Errorf("got %+v, want %+v", d.Headings, want)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	in := `<a href="/about">a</a>`
+
+	// Without absolute-urls running first the link is still the site's own.
+	got, _ := apply(t, in, "https://warrenb95.dev", "", "external-links", "absolute-urls")
+	if want := `<a href="https://warrenb95.dev/about">a</a>`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	got, _ = apply(t, in, "https://warrenb95.dev", "", "absolute-urls", "external-links")
+	if want := `<a href="https://warrenb95.dev/about" target="_blank" rel="noopener noreferrer">a</a>`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	got, _ = apply(t, in, "", "", "emoji")
+	if got != in {
+		t.Errorf("unknown transformer changed the html to %s", got)
+	}
+
+	for _, name := range Defaults {
+		if !Registered(name) {
+			t.Errorf("default %q isn't registered", name)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+// Package views embeds the HTML templates so the site can be deployed as a single binary.
+package views
+
+import "embed"
+
+//go:embed *.html
+var FS embed.FS